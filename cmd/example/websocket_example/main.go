@@ -114,81 +114,61 @@ func runAll(ctx context.Context, client *oddrip.Client, log *os.File, wsHost, ws
 
 	var tickerSID int
 	var orderbookSID int
-	var subs []types.SubscribedResponse
+	var subs []*oddrip.Subscription
 
-	logCall("Subscribe (ticker, single market)",
-		types.SubscribeCommand{Cmd: "subscribe", Params: types.SubscribeParams{
-			Channels:     []string{types.WSChannelTicker},
-			MarketTicker: exampleMarketTicker,
-		}},
-		func() (interface{}, error) {
-			subs, err = conn.Subscribe(ctx, types.SubscribeParams{
-				Channels:     []string{types.WSChannelTicker},
-				MarketTicker: exampleMarketTicker,
-			})
+	subscribe := func(params types.SubscribeParams, sid *int) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			sub, err := conn.Subscribe(ctx, params)
 			if err != nil {
 				return nil, err
 			}
-			if len(subs) > 0 {
-				tickerSID = subs[0].Msg.SID
+			subs = append(subs, sub)
+			if sids := sub.SIDs(); sid != nil && len(sids) > 0 {
+				*sid = sids[0]
 			}
-			return subs, nil
-		})
+			return map[string]interface{}{"sids": sub.SIDs(), "channels": sub.Channels()}, nil
+		}
+	}
+
+	tickerParams := types.SubscribeParams{
+		Channels:     []string{types.WSChannelTicker},
+		MarketTicker: exampleMarketTicker,
+	}
+	logCall("Subscribe (ticker, single market)",
+		types.SubscribeCommand{Cmd: "subscribe", Params: tickerParams},
+		subscribe(tickerParams, &tickerSID))
 
+	orderbookParams := types.SubscribeParams{
+		Channels:     []string{types.WSChannelOrderbookDelta},
+		MarketTicker: exampleMarketTicker,
+	}
 	logCall("Subscribe (orderbook_delta, single market)",
-		types.SubscribeCommand{Cmd: "subscribe", Params: types.SubscribeParams{
-			Channels:     []string{types.WSChannelOrderbookDelta},
-			MarketTicker: exampleMarketTicker,
-		}},
-		func() (interface{}, error) {
-			s, err := conn.Subscribe(ctx, types.SubscribeParams{
-				Channels:     []string{types.WSChannelOrderbookDelta},
-				MarketTicker: exampleMarketTicker,
-			})
-			if err != nil {
-				return nil, err
-			}
-			if len(s) > 0 {
-				orderbookSID = s[0].Msg.SID
-			}
-			return s, nil
-		})
+		types.SubscribeCommand{Cmd: "subscribe", Params: orderbookParams},
+		subscribe(orderbookParams, &orderbookSID))
 
+	tradeParams := types.SubscribeParams{
+		Channels:     []string{types.WSChannelTrade},
+		MarketTicker: exampleMarketTicker,
+	}
 	logCall("Subscribe (trade, optional market)",
-		types.SubscribeCommand{Cmd: "subscribe", Params: types.SubscribeParams{
-			Channels:     []string{types.WSChannelTrade},
-			MarketTicker: exampleMarketTicker,
-		}},
-		func() (interface{}, error) {
-			return conn.Subscribe(ctx, types.SubscribeParams{
-				Channels:     []string{types.WSChannelTrade},
-				MarketTicker: exampleMarketTicker,
-			})
-		})
+		types.SubscribeCommand{Cmd: "subscribe", Params: tradeParams},
+		subscribe(tradeParams, nil))
 
+	lifecycleParams := types.SubscribeParams{
+		Channels: []string{types.WSChannelMarketLifecycle},
+	}
 	logCall("Subscribe (market_lifecycle_v2, no market filter)",
-		types.SubscribeCommand{Cmd: "subscribe", Params: types.SubscribeParams{
-			Channels: []string{types.WSChannelMarketLifecycle},
-		}},
-		func() (interface{}, error) {
-			return conn.Subscribe(ctx, types.SubscribeParams{
-				Channels: []string{types.WSChannelMarketLifecycle},
-			})
-		})
+		types.SubscribeCommand{Cmd: "subscribe", Params: lifecycleParams},
+		subscribe(lifecycleParams, nil))
 
+	multiParams := types.SubscribeParams{
+		Channels:            []string{types.WSChannelTicker},
+		MarketTickers:       []string{exampleMarketTicker, "KXETH-24DEC31-T5000"},
+		SendInitialSnapshot: &trueVal,
+	}
 	logCall("Subscribe (ticker, multiple markets, send_initial_snapshot)",
-		types.SubscribeCommand{Cmd: "subscribe", Params: types.SubscribeParams{
-			Channels:            []string{types.WSChannelTicker},
-			MarketTickers:       []string{exampleMarketTicker, "KXETH-24DEC31-T5000"},
-			SendInitialSnapshot: &trueVal,
-		}},
-		func() (interface{}, error) {
-			return conn.Subscribe(ctx, types.SubscribeParams{
-				Channels:            []string{types.WSChannelTicker},
-				MarketTickers:       []string{exampleMarketTicker, "KXETH-24DEC31-T5000"},
-				SendInitialSnapshot: &trueVal,
-			})
-		})
+		types.SubscribeCommand{Cmd: "subscribe", Params: multiParams},
+		subscribe(multiParams, nil))
 
 	logCall("ListSubscriptions",
 		types.ListSubscriptionsCommand{Cmd: "list_subscriptions"},
@@ -216,18 +196,34 @@ func runAll(ctx context.Context, client *oddrip.Client, log *os.File, wsHost, ws
 			})
 	}
 
+	// Each subscription has its own channel; merge them for logging.
+	recvCtx, stopRecv := context.WithTimeout(ctx, 5*time.Second)
+	defer stopRecv()
+	merged := make(chan *types.WSMessage)
+	sources := []<-chan *types.WSMessage{conn.Messages()}
+	for _, sub := range subs {
+		sources = append(sources, sub.Messages())
+	}
+	for _, ch := range sources {
+		go func() {
+			for msg := range ch {
+				select {
+				case merged <- msg:
+				case <-recvCtx.Done():
+					return
+				}
+			}
+		}()
+	}
+
 	fmt.Fprintf(log, "=== Receive messages (5s) ===\n")
-	deadline := time.After(5 * time.Second)
 	received := 0
 loop:
 	for {
 		select {
-		case <-deadline:
+		case <-recvCtx.Done():
 			break loop
-		case msg, ok := <-conn.Messages():
-			if !ok {
-				break loop
-			}
+		case msg := <-merged:
 			received++
 			fmt.Fprintf(log, "Message #%d type=%s sid=%d seq=%d\n", received, msg.Type, msg.SID, msg.Seq)
 			fmt.Fprintf(log, "Raw msg:\n%s\n\n", string(msg.Msg))
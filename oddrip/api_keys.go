@@ -0,0 +1,39 @@
+package oddrip
+
+import (
+	"context"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+type APIKeysService struct {
+	client *Client
+}
+
+func (s *APIKeysService) List(ctx context.Context) (*types.GetAPIKeysResponse, error) {
+	var out types.GetAPIKeysResponse
+	if err := s.client.get(ctx, joinPath("api_keys"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *APIKeysService) Create(ctx context.Context, req *types.CreateAPIKeyRequest) (*types.CreateAPIKeyResponse, error) {
+	var out types.CreateAPIKeyResponse
+	if err := s.client.post(ctx, joinPath("api_keys"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *APIKeysService) Generate(ctx context.Context, req *types.GenerateAPIKeyRequest) (*types.GenerateAPIKeyResponse, error) {
+	var out types.GenerateAPIKeyResponse
+	if err := s.client.post(ctx, joinPath("api_keys", "generate"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *APIKeysService) Delete(ctx context.Context, apiKeyID string) error {
+	return s.client.delete(ctx, joinPath("api_keys", apiKeyID), nil, nil, nil)
+}
@@ -19,6 +19,18 @@ func ParsePrivateKeyFromPEM(pemBytes []byte) (*rsa.PrivateKey, error) {
 	return auth.ParsePrivateKeyFromPEM(pemBytes)
 }
 
+const DefaultRSAKeyBits = 2048
+
+// GenerateRSAKeyPair creates an RSA key locally and returns the private key as
+// PKCS#8 PEM (readable by ParsePrivateKeyFromPEM) and the public key as PKIX PEM,
+// suitable for APIKeysService.Create. bits <= 0 uses DefaultRSAKeyBits.
+func GenerateRSAKeyPair(bits int) (privatePEM, publicPEM []byte, err error) {
+	if bits <= 0 {
+		bits = DefaultRSAKeyBits
+	}
+	return auth.GenerateRSAKeyPair(bits)
+}
+
 type StaticHeaders struct {
 	Headers http.Header
 }
@@ -0,0 +1,243 @@
+package oddrip
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+const (
+	defaultBackfillWindow      = 24 * time.Hour
+	defaultBackfillParallelism = 4
+	defaultBackfillRate        = 10
+)
+
+type backfillWindow struct {
+	minTs, maxTs int64
+}
+
+type backfillResult[T any] struct {
+	items []T
+	err   error
+}
+
+type backfillJob[T any] struct {
+	w      backfillWindow
+	result chan backfillResult[T]
+}
+
+// BackfillTrades pages GET /markets/trades over opts' time range concurrently
+// and yields trades oldest first, de-duplicated by TradeID.
+//
+// [MinTs, MaxTs] (unix seconds) is split into Window-sized slices (default
+// 24h) that are paged through Parallelism at a time (default 4).
+// RequestsPerSecond caps page requests across all workers; when zero it is
+// taken from Account.GetAPILimits (read_limit), falling back to 10.
+// OnCheckpoint is called after every slice has been yielded; pass the last
+// checkpoint back as Resume to continue a run.
+func (s *MarketsService) BackfillTrades(ctx context.Context, opts *types.BackfillOpts) iter.Seq2[types.Trade, error] {
+	return backfill(ctx, s.client, opts,
+		func(ctx context.Context, o *types.BackfillOpts, w backfillWindow, cursor string) ([]types.Trade, string, error) {
+			resp, err := s.GetTrades(ctx, &types.GetTradesOpts{Ticker: o.Ticker, MinTs: &w.minTs, MaxTs: &w.maxTs, Limit: o.Limit, Cursor: cursor})
+			if err != nil {
+				return nil, "", err
+			}
+			return resp.Trades, resp.Cursor, nil
+		},
+		func(t types.Trade) string { return t.TradeID },
+		func(t types.Trade) int64 {
+			ts, _ := parseTs(t.CreatedTime)
+			return ts
+		},
+	)
+}
+
+// BackfillFills pages GET /portfolio/fills over opts' time range concurrently
+// and yields fills oldest first, de-duplicated by FillID.
+// Options behave as for Markets.BackfillTrades.
+func (s *PortfolioService) BackfillFills(ctx context.Context, opts *types.BackfillOpts) iter.Seq2[types.Fill, error] {
+	return backfill(ctx, s.client, opts,
+		func(ctx context.Context, o *types.BackfillOpts, w backfillWindow, cursor string) ([]types.Fill, string, error) {
+			resp, err := s.GetFills(ctx, &types.GetFillsOpts{Ticker: o.Ticker, MinTs: &w.minTs, MaxTs: &w.maxTs, Limit: o.Limit, Cursor: cursor})
+			if err != nil {
+				return nil, "", err
+			}
+			return resp.Fills, resp.Cursor, nil
+		},
+		func(f types.Fill) string { return f.FillID },
+		func(f types.Fill) int64 {
+			if f.Ts != nil {
+				return *f.Ts
+			}
+			ts, _ := parseTs(f.CreatedTime)
+			return ts
+		},
+	)
+}
+
+func backfill[T any](
+	ctx context.Context,
+	c *Client,
+	opts *types.BackfillOpts,
+	fetch func(ctx context.Context, o *types.BackfillOpts, w backfillWindow, cursor string) ([]T, string, error),
+	id func(T) string,
+	ts func(T) int64,
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if opts == nil {
+			yield(zero, errors.New("backfill: opts are required"))
+			return
+		}
+		o := *opts
+		if o.MaxTs < o.MinTs {
+			yield(zero, errors.New("backfill: MaxTs is before MinTs"))
+			return
+		}
+		if o.Resume != nil && o.Resume.NextTs > o.MinTs {
+			o.MinTs = o.Resume.NextTs
+		}
+		if o.MinTs > o.MaxTs {
+			return
+		}
+		if o.Window <= 0 {
+			o.Window = defaultBackfillWindow
+		}
+		if o.Parallelism <= 0 {
+			o.Parallelism = defaultBackfillParallelism
+		}
+		if o.RequestsPerSecond <= 0 {
+			o.RequestsPerSecond = defaultBackfillRate
+			if limits, err := c.Account.GetAPILimits(ctx); err == nil && limits.ReadLimit > 0 {
+				o.RequestsPerSecond = float64(limits.ReadLimit)
+			}
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		limiter := newRateLimiter(o.RequestsPerSecond)
+		// Windows are generated as workers free up: sem bounds windows that
+		// are in flight or fetched but not yet yielded, and started hands
+		// them to the consumer in order.
+		sem := make(chan struct{}, o.Parallelism)
+		started := make(chan backfillJob[T], o.Parallelism)
+		go func() {
+			defer close(started)
+			for w := range windows(o.MinTs, o.MaxTs, int64(o.Window/time.Second)) {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				job := backfillJob[T]{w: w, result: make(chan backfillResult[T], 1)}
+				go func() {
+					var r backfillResult[T]
+					for item, err := range paginate(ctx, "", func(cursor string) ([]T, string, error) {
+						if err := limiter.wait(ctx); err != nil {
+							return nil, "", err
+						}
+						return fetch(ctx, &o, w, cursor)
+					}) {
+						if err != nil {
+							r.err = err
+							break
+						}
+						r.items = append(r.items, item)
+					}
+					job.result <- r
+				}()
+				started <- job
+			}
+		}()
+
+		var prev map[string]struct{}
+		for job := range started {
+			var r backfillResult[T]
+			select {
+			case r = <-job.result:
+			case <-ctx.Done():
+				yield(zero, ctx.Err())
+				return
+			}
+			<-sem
+			if r.err != nil {
+				yield(zero, r.err)
+				return
+			}
+			sort.SliceStable(r.items, func(a, b int) bool { return ts(r.items[a]) < ts(r.items[b]) })
+			cur := make(map[string]struct{}, len(r.items))
+			for _, item := range r.items {
+				k := id(item)
+				if _, dup := prev[k]; dup {
+					continue
+				}
+				if _, dup := cur[k]; dup {
+					continue
+				}
+				cur[k] = struct{}{}
+				if !yield(item, nil) {
+					return
+				}
+			}
+			prev = cur
+			if o.OnCheckpoint != nil {
+				if err := o.OnCheckpoint(types.BackfillCheckpoint{NextTs: job.w.maxTs + 1}); err != nil {
+					yield(zero, err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// windows yields non-overlapping inclusive slices of [minTs, maxTs] of size
+// seconds.
+func windows(minTs, maxTs, size int64) iter.Seq[backfillWindow] {
+	if size <= 0 {
+		size = 1
+	}
+	return func(yield func(backfillWindow) bool) {
+		for start := minTs; start <= maxTs; start += size {
+			if !yield(backfillWindow{minTs: start, maxTs: min(start+size-1, maxTs)}) {
+				return
+			}
+		}
+	}
+}
+
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	d := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
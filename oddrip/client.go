@@ -32,12 +32,23 @@ type Client struct {
 	auth       AuthProvider
 	retry      retry.Config
 
-	Exchange  *ExchangeService
-	Markets   *MarketsService
-	Orders    *OrdersService
-	Portfolio *PortfolioService
-	Account   *AccountService
-	Events    *EventsService
+	Exchange                *ExchangeService
+	Markets                 *MarketsService
+	Orders                  *OrdersService
+	Portfolio               *PortfolioService
+	Account                 *AccountService
+	Events                  *EventsService
+	OrderGroups             *OrderGroupsService
+	Subaccounts             *SubaccountsService
+	Communications          *CommunicationsService
+	APIKeys                 *APIKeysService
+	Series                  *SeriesService
+	Milestones              *MilestonesService
+	MultivariateCollections *MultivariateCollectionsService
+	StructuredTargets       *StructuredTargetsService
+	Search                  *SearchService
+	IncentivePrograms       *IncentiveProgramsService
+	FCM                     *FCMService
 }
 
 type Option func(*Client)
@@ -93,6 +104,17 @@ func New(opts ...Option) *Client {
 	c.Portfolio = &PortfolioService{client: c}
 	c.Account = &AccountService{client: c}
 	c.Events = &EventsService{client: c}
+	c.OrderGroups = &OrderGroupsService{client: c}
+	c.Subaccounts = &SubaccountsService{client: c}
+	c.Communications = &CommunicationsService{client: c}
+	c.APIKeys = &APIKeysService{client: c}
+	c.Series = &SeriesService{client: c}
+	c.Milestones = &MilestonesService{client: c}
+	c.MultivariateCollections = &MultivariateCollectionsService{client: c}
+	c.StructuredTargets = &StructuredTargetsService{client: c}
+	c.Search = &SearchService{client: c}
+	c.IncentivePrograms = &IncentiveProgramsService{client: c}
+	c.FCM = &FCMService{client: c}
 	return c
 }
 
@@ -167,8 +189,8 @@ func (c *Client) post(ctx context.Context, path string, body interface{}, out in
 	return c.do(ctx, http.MethodPost, path, nil, body, out)
 }
 
-func (c *Client) put(ctx context.Context, path string, body interface{}, out interface{}) error {
-	return c.do(ctx, http.MethodPut, path, nil, body, out)
+func (c *Client) put(ctx context.Context, path string, query url.Values, body interface{}, out interface{}) error {
+	return c.do(ctx, http.MethodPut, path, query, body, out)
 }
 
 func (c *Client) delete(ctx context.Context, path string, query url.Values, body interface{}, out interface{}) error {
@@ -4,9 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/UTXOnly/oddrip/oddrip/types"
 )
@@ -183,3 +190,925 @@ func TestPortfolio_ListHistoricalFills_RequestPath(t *testing.T) {
 		t.Fatalf("path: %v", mt.req)
 	}
 }
+
+func TestOrderGroups_Create_RequestPathAndBody(t *testing.T) {
+	body := []byte(`{"order_group_id":"og-1"}`)
+	mt := &mockTransport{statusCode: 201, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	limit := int64(50)
+	got, err := client.OrderGroups.Create(ctx, &types.CreateOrderGroupRequest{ContractsLimit: &limit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.OrderGroupID != "og-1" {
+		t.Errorf("OrderGroupID: %s", got.OrderGroupID)
+	}
+	if mt.req == nil || mt.req.Method != http.MethodPost || mt.req.URL.Path != "/trade-api/v2/portfolio/order_groups/create" {
+		t.Fatalf("request: %v", mt.req)
+	}
+	var sent map[string]interface{}
+	reqBody, _ := io.ReadAll(mt.req.Body)
+	if json.Unmarshal(reqBody, &sent) != nil || sent["contracts_limit"].(float64) != 50 {
+		t.Fatalf("body: %s", reqBody)
+	}
+}
+
+func TestOrderGroups_Reset_MethodAndQuery(t *testing.T) {
+	mt := &mockTransport{statusCode: 200, body: []byte(`{}`)}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	sub := 2
+	if err := client.OrderGroups.Reset(ctx, "og-1", &sub); err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.Method != http.MethodPut || mt.req.URL.Path != "/trade-api/v2/portfolio/order_groups/og-1/reset" {
+		t.Fatalf("request: %v", mt.req)
+	}
+	if mt.req.URL.Query().Get("subaccount") != "2" {
+		t.Fatalf("query: %v", mt.req.URL.Query())
+	}
+}
+
+func TestSubaccounts_ListTransfers_QueryAndPath(t *testing.T) {
+	body := []byte(`{"transfers":[{"transfer_id":"tr-1","from_subaccount":0,"to_subaccount":3,"amount_cents":2500,"created_ts":1}],"cursor":"next"}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	limit := int64(10)
+	got, err := client.Subaccounts.ListTransfers(ctx, &types.GetSubaccountTransfersOpts{Limit: &limit, Cursor: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/portfolio/subaccounts/transfers" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	q := mt.req.URL.Query()
+	if q.Get("limit") != "10" || q.Get("cursor") != "abc" {
+		t.Fatalf("query: %v", q)
+	}
+	if len(got.Transfers) != 1 || got.Transfers[0].ToSubaccount != 3 || got.Transfers[0].AmountCents != 2500 || got.Cursor != "next" {
+		t.Fatalf("transfers: %+v", got)
+	}
+}
+
+func TestCommunications_ListQuotes_QueryAndPath(t *testing.T) {
+	body := []byte(`{"quotes":[{"id":"q1","rfq_id":"r1","market_ticker":"MVE-X","yes_bid_dollars":"0.4000","no_bid_dollars":"0.5500","status":"open"}],"cursor":""}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	got, err := client.Communications.ListQuotes(ctx, &types.GetQuotesOpts{RFQID: "r1", Status: types.QuoteStatusOpen})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/communications/quotes" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	q := mt.req.URL.Query()
+	if q.Get("rfq_id") != "r1" || q.Get("status") != "open" {
+		t.Fatalf("query: %v", q)
+	}
+	if len(got.Quotes) != 1 || got.Quotes[0].YesBidDollars != "0.4000" {
+		t.Fatalf("quotes: %+v", got.Quotes)
+	}
+}
+
+func TestCommunications_AcceptQuote_InvalidSide(t *testing.T) {
+	client := New()
+	ctx := context.Background()
+	if err := client.Communications.AcceptQuote(ctx, "q1", &types.AcceptQuoteRequest{AcceptedSide: "maybe"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestAPIKeys_Create_WithGeneratedKey(t *testing.T) {
+	privPEM, pubPEM, err := GenerateRSAKeyPair(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParsePrivateKeyFromPEM(privPEM); err != nil {
+		t.Fatalf("ParsePrivateKeyFromPEM: %v", err)
+	}
+
+	mt := &mockTransport{statusCode: 201, body: []byte(`{"api_key_id":"key-1"}`)}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	got, err := client.APIKeys.Create(ctx, &types.CreateAPIKeyRequest{
+		Name:      "ops",
+		PublicKey: string(pubPEM),
+		Scopes:    []string{types.APIKeyScopeRead},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.APIKeyID != "key-1" {
+		t.Errorf("APIKeyID: %s", got.APIKeyID)
+	}
+	if mt.req == nil || mt.req.Method != http.MethodPost || mt.req.URL.Path != "/trade-api/v2/api_keys" {
+		t.Fatalf("request: %v", mt.req)
+	}
+	var sent types.CreateAPIKeyRequest
+	reqBody, _ := io.ReadAll(mt.req.Body)
+	if json.Unmarshal(reqBody, &sent) != nil || sent.PublicKey != string(pubPEM) {
+		t.Fatalf("body: %s", reqBody)
+	}
+}
+
+func TestSeries_GetMarketCandlesticks_QueryAndPath(t *testing.T) {
+	body := []byte(`{"ticker":"X","candlesticks":[{"end_period_ts":60,"yes_bid":{"open_dollars":"0.4000","low_dollars":"0.3900","high_dollars":"0.4100","close_dollars":"0.4000"},"price":{"close_dollars":null},"volume_fp":"5.00","open_interest_fp":"12.00"}]}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	got, err := client.Series.GetMarketCandlesticks(ctx, "SER", "X", &types.GetMarketCandlesticksOpts{
+		StartTs:        1,
+		EndTs:          2,
+		PeriodInterval: types.PeriodInterval1Min,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/series/SER/markets/X/candlesticks" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	q := mt.req.URL.Query()
+	if q.Get("start_ts") != "1" || q.Get("end_ts") != "2" || q.Get("period_interval") != "1" {
+		t.Fatalf("query: %v", q)
+	}
+	if len(got.Candlesticks) != 1 || got.Candlesticks[0].YesBid.LowDollars != "0.3900" || got.Candlesticks[0].Price.CloseDollars != nil {
+		t.Fatalf("candlesticks: %+v", got.Candlesticks)
+	}
+}
+
+func TestSeries_GetMarketCandlesticks_InvalidPeriod(t *testing.T) {
+	client := New()
+	ctx := context.Background()
+	_, err := client.Series.GetMarketCandlesticks(ctx, "SER", "X", &types.GetMarketCandlesticksOpts{
+		StartTs:        1,
+		EndTs:          2,
+		PeriodInterval: 5,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func jsonResponse(req *http.Request, v interface{}) *http.Response {
+	body, _ := json.Marshal(v)
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     make(http.Header),
+		Body:       &mockBody{data: body},
+		Request:    req,
+	}
+	resp.Header.Set("Content-Type", "application/json")
+	return resp
+}
+
+func TestMarkets_GetCandlesticksByTicker_SplitsTickers(t *testing.T) {
+	var calls []int
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/trade-api/v2/markets/candlesticks" {
+			t.Errorf("path: %s", req.URL.Path)
+		}
+		tickers := strings.Split(req.URL.Query().Get("market_tickers"), ",")
+		calls = append(calls, len(tickers))
+		markets := make([]types.MarketCandlesticks, 0, len(tickers))
+		for _, tk := range tickers {
+			markets = append(markets, types.MarketCandlesticks{
+				MarketTicker: tk,
+				Candlesticks: []types.MarketCandlestick{{EndPeriodTs: 60}},
+			})
+		}
+		return jsonResponse(req, types.BatchGetMarketCandlesticksResponse{Markets: markets}), nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+	ctx := context.Background()
+
+	tickers := make([]string, 250)
+	for i := range tickers {
+		tickers[i] = fmt.Sprintf("MKT-%d", i)
+	}
+	got, err := client.Markets.GetCandlesticksByTicker(ctx, &types.BatchGetMarketCandlesticksOpts{
+		MarketTickers:  tickers,
+		StartTs:        0,
+		EndTs:          3600,
+		PeriodInterval: types.PeriodInterval1Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 || calls[0] != 100 || calls[2] != 50 {
+		t.Fatalf("calls: %v", calls)
+	}
+	if len(got) != 250 || len(got["MKT-249"]) != 1 {
+		t.Fatalf("got %d tickers", len(got))
+	}
+}
+
+func TestMarkets_GetCandlesticksByTicker_StaysUnderCandleCap(t *testing.T) {
+	var calls []string
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		start, _ := strconv.ParseInt(q.Get("start_ts"), 10, 64)
+		end, _ := strconv.ParseInt(q.Get("end_ts"), 10, 64)
+		tickers := strings.Split(q.Get("market_tickers"), ",")
+		perTicker := (end-start)/60 + 1
+		if q.Get("include_latest_before_start") == "true" {
+			perTicker++
+		}
+		if n := perTicker * int64(len(tickers)); n > 10000 {
+			t.Errorf("%d candles in one request: %v", n, q)
+		}
+		calls = append(calls, fmt.Sprintf("%d:%d-%d:%s", len(tickers), start, end, q.Get("include_latest_before_start")))
+		markets := make([]types.MarketCandlesticks, 0, len(tickers))
+		for _, tk := range tickers {
+			markets = append(markets, types.MarketCandlesticks{MarketTicker: tk, Candlesticks: []types.MarketCandlestick{{EndPeriodTs: end}}})
+		}
+		return jsonResponse(req, types.BatchGetMarketCandlesticksResponse{Markets: markets}), nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+	ctx := context.Background()
+	yes := true
+
+	// 5,000 one-minute candles plus the latest-before-start one: one ticker
+	// per request.
+	_, err := client.Markets.GetCandlesticksByTicker(ctx, &types.BatchGetMarketCandlesticksOpts{
+		MarketTickers: []string{"A", "B"}, StartTs: 0, EndTs: 4999 * 60,
+		PeriodInterval: types.PeriodInterval1Min, IncludeLatestBeforeStart: &yes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(calls) != "[1:0-299940:true 1:0-299940:true]" {
+		t.Fatalf("calls: %v", calls)
+	}
+
+	// 15,001 candles for one ticker: the range is split, and only the first
+	// part asks for the candle before start.
+	calls = nil
+	got, err := client.Markets.GetCandlesticksByTicker(ctx, &types.BatchGetMarketCandlesticksOpts{
+		MarketTickers: []string{"A"}, StartTs: 0, EndTs: 15000 * 60,
+		PeriodInterval: types.PeriodInterval1Min, IncludeLatestBeforeStart: &yes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(calls) != "[1:0-599880:true 1:599881-900000:]" || len(got["A"]) != 2 {
+		t.Fatalf("calls: %v got %v", calls, got)
+	}
+}
+
+func TestMarkets_BatchGetCandlesticks_TooManyTickers(t *testing.T) {
+	client := New()
+	_, err := client.Markets.BatchGetCandlesticks(context.Background(), &types.BatchGetMarketCandlesticksOpts{
+		MarketTickers:  make([]string, 101),
+		PeriodInterval: 1,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestEvents_GetCandlesticks_QueryAndPath(t *testing.T) {
+	body := []byte(`{"market_tickers":["X","Y"],"market_candlesticks":[[{"end_period_ts":3600,"yes_bid":{"close_dollars":"0.4000"},"volume_fp":"5.00"}],[]],"adjusted_end_ts":3600}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	got, err := client.Events.GetCandlesticks(ctx, "SER", "EV", &types.GetEventCandlesticksOpts{
+		StartTs:        1,
+		EndTs:          7200,
+		PeriodInterval: types.PeriodInterval1Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/series/SER/events/EV/candlesticks" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	q := mt.req.URL.Query()
+	if q.Get("start_ts") != "1" || q.Get("end_ts") != "7200" || q.Get("period_interval") != "60" {
+		t.Fatalf("query: %v", q)
+	}
+	if len(got.MarketTickers) != 2 || len(got.MarketCandlesticks) != 2 || got.AdjustedEndTs != 3600 {
+		t.Fatalf("response: %+v", got)
+	}
+	if c := got.MarketCandlesticks[0]; len(c) != 1 || c[0].YesBid.CloseDollars != "0.4000" || c[0].VolumeFp != "5.00" {
+		t.Fatalf("candlesticks: %+v", got.MarketCandlesticks)
+	}
+}
+
+func TestEvents_GetCandlesticks_InvalidPeriod(t *testing.T) {
+	client := New()
+	ctx := context.Background()
+	_, err := client.Events.GetCandlesticks(ctx, "SER", "EV", &types.GetEventCandlesticksOpts{
+		StartTs:        1,
+		EndTs:          2,
+		PeriodInterval: 5,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestEvents_GetForecastPercentileHistory_Query(t *testing.T) {
+	body := []byte(`{"forecast_history":[{"event_ticker":"EV","end_period_ts":60,"period_interval":1,"percentile_points":[{"percentile":5000,"raw_numerical_forecast":3.1,"numerical_forecast":3.1,"formatted_forecast":"3.1%"}]}]}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	period := types.PeriodInterval5Sec
+	got, err := client.Events.GetForecastPercentileHistory(ctx, "SER", "EV", &types.GetEventForecastPercentileHistoryOpts{
+		Percentiles:    []int{2500, 5000, 7500},
+		StartTs:        1,
+		EndTs:          2,
+		PeriodInterval: &period,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/series/SER/events/EV/forecast_percentile_history" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	q := mt.req.URL.Query()
+	if len(q["percentiles"]) != 3 || q.Get("period_interval") != "0" {
+		t.Fatalf("query: %v", q)
+	}
+	if len(got.ForecastHistory) != 1 || got.ForecastHistory[0].PercentilePoints[0].FormattedForecast != "3.1%" {
+		t.Fatalf("history: %+v", got.ForecastHistory)
+	}
+}
+
+func TestEvents_GetForecastPercentileHistory_RequiresPeriod(t *testing.T) {
+	mt := &mockTransport{statusCode: 200, body: []byte(`{"forecast_history":[]}`)}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	_, err := client.Events.GetForecastPercentileHistory(context.Background(), "SER", "EV", &types.GetEventForecastPercentileHistoryOpts{
+		Percentiles: []int{5000},
+		StartTs:     1,
+		EndTs:       2,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if mt.req != nil {
+		t.Fatalf("unexpected request: %v", mt.req.URL)
+	}
+}
+
+func TestMilestones_List_DefaultLimit(t *testing.T) {
+	mt := &mockTransport{statusCode: 200, body: []byte(`{"milestones":[],"cursor":""}`)}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	if _, err := client.Milestones.List(ctx, &types.GetMilestonesOpts{Category: "sports"}); err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/milestones" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	q := mt.req.URL.Query()
+	if q.Get("limit") != "100" || q.Get("category") != "sports" {
+		t.Fatalf("query: %v", q)
+	}
+}
+
+func TestMilestones_GetLiveDataBatch_Query(t *testing.T) {
+	body := []byte(`{"live_datas":[{"type":"football","milestone_id":"m1","details":{"home_score":7}}]}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	got, err := client.Milestones.GetLiveDataBatch(ctx, []string{"m1", "m2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/live_data/batch" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	if ids := mt.req.URL.Query()["milestone_ids"]; len(ids) != 2 || ids[1] != "m2" {
+		t.Fatalf("milestone_ids: %v", ids)
+	}
+	if len(got.LiveDatas) != 1 || got.LiveDatas[0].Details["home_score"].(float64) != 7 {
+		t.Fatalf("live data: %+v", got.LiveDatas)
+	}
+}
+
+func TestMultivariateCollections_LookupTickers_MethodAndBody(t *testing.T) {
+	mt := &mockTransport{statusCode: 200, body: []byte(`{"event_ticker":"KXMVE-EV","market_ticker":"KXMVE-EV-M1"}`)}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	got, err := client.MultivariateCollections.LookupTickers(ctx, "KXMVE", &types.LookupTickersForMarketInMultivariateEventCollectionRequest{
+		SelectedMarkets: []types.TickerPair{
+			{MarketTicker: "A-1", EventTicker: "A", Side: types.OrderSideYes},
+			{MarketTicker: "B-1", EventTicker: "B", Side: types.OrderSideNo},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MarketTicker != "KXMVE-EV-M1" {
+		t.Errorf("MarketTicker: %s", got.MarketTicker)
+	}
+	if mt.req == nil || mt.req.Method != http.MethodPut || mt.req.URL.Path != "/trade-api/v2/multivariate_event_collections/KXMVE/lookup" {
+		t.Fatalf("request: %v", mt.req)
+	}
+	var sent types.LookupTickersForMarketInMultivariateEventCollectionRequest
+	reqBody, _ := io.ReadAll(mt.req.Body)
+	if json.Unmarshal(reqBody, &sent) != nil || len(sent.SelectedMarkets) != 2 || sent.SelectedMarkets[1].Side != "no" {
+		t.Fatalf("body: %s", reqBody)
+	}
+}
+
+func TestSearch_GetFiltersBySport_Decode(t *testing.T) {
+	body := []byte(`{"filters_by_sports":{"Football":{"scopes":["Games"],"competitions":{"NFL":{"scopes":["Games","Futures"]}}}},"sport_ordering":["Football"]}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	got, err := client.Search.GetFiltersBySport(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/search/filters_by_sport" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	if len(got.FiltersBySports["Football"].Competitions["NFL"].Scopes) != 2 || got.SportOrdering[0] != "Football" {
+		t.Fatalf("filters: %+v", got)
+	}
+}
+
+func TestStructuredTargets_List_QueryAndDecode(t *testing.T) {
+	body := []byte(`{"structured_targets":[{"id":"st1","name":"Kansas City Chiefs","type":"basketball_team","source_ids":{"sportradar":"sr:1"}}],"cursor":"c2"}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	pageSize := 50
+	got, err := client.StructuredTargets.List(ctx, &types.GetStructuredTargetsOpts{
+		Type:        "basketball_team",
+		Competition: "NBA",
+		PageSize:    &pageSize,
+		Cursor:      "c1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/structured_targets" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	q := mt.req.URL.Query()
+	if q.Get("type") != "basketball_team" || q.Get("competition") != "NBA" || q.Get("page_size") != "50" || q.Get("cursor") != "c1" {
+		t.Fatalf("query: %v", q)
+	}
+	if len(got.StructuredTargets) != 1 || got.StructuredTargets[0].SourceIDs["sportradar"] != "sr:1" || got.Cursor != "c2" {
+		t.Fatalf("targets: %+v", got)
+	}
+}
+
+func TestStructuredTargets_Get_Path(t *testing.T) {
+	body := []byte(`{"structured_target":{"id":"st1","name":"Kansas City Chiefs","type":"basketball_team"}}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+
+	got, err := client.StructuredTargets.Get(context.Background(), "st1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/structured_targets/st1" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	if got.StructuredTarget.Name != "Kansas City Chiefs" {
+		t.Fatalf("target: %+v", got)
+	}
+}
+
+func TestIncentivePrograms_List_Query(t *testing.T) {
+	body := []byte(`{"incentive_programs":[{"id":"ip1","market_ticker":"X","incentive_type":"liquidity","period_reward":500000,"paid_out":false}],"next_cursor":"c2"}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	got, err := client.IncentivePrograms.List(ctx, &types.GetIncentiveProgramsOpts{
+		Status: types.IncentiveStatusActive,
+		Type:   types.IncentiveTypeLiquidity,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/incentive_programs" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	q := mt.req.URL.Query()
+	if q.Get("status") != "active" || q.Get("type") != "liquidity" {
+		t.Fatalf("query: %v", q)
+	}
+	if len(got.IncentivePrograms) != 1 || got.IncentivePrograms[0].PeriodReward != 500000 || got.NextCursor != "c2" {
+		t.Fatalf("programs: %+v", got)
+	}
+}
+
+func TestFCM_ListPositions_QueryAndDecode(t *testing.T) {
+	body := []byte(`{"market_positions":[{"ticker":"X","position_fp":"3.00","last_updated_ts":"2026-01-01T00:00:00Z"}],"event_positions":[],"cursor":""}`)
+	mt := &mockTransport{statusCode: 200, body: body}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	got, err := client.FCM.ListPositions(ctx, &types.GetFCMPositionsOpts{
+		SubtraderID:      "sub-1",
+		SettlementStatus: types.SettlementStatusUnsettled,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/fcm/positions" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	q := mt.req.URL.Query()
+	if q.Get("subtrader_id") != "sub-1" || q.Get("settlement_status") != "unsettled" {
+		t.Fatalf("query: %v", q)
+	}
+	if len(got.MarketPositions) != 1 || got.MarketPositions[0].PositionFp != "3.00" {
+		t.Fatalf("positions: %+v", got.MarketPositions)
+	}
+}
+
+func TestFCM_ListOrders_SubtraderRequired(t *testing.T) {
+	client := New()
+	if _, err := client.FCM.ListOrders(context.Background(), &types.GetFCMOrdersOpts{}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestPortfolio_GetRestingOrderTotalValue_Subaccount(t *testing.T) {
+	mt := &mockTransport{statusCode: 200, body: []byte(`{"total_resting_order_value":125000}`)}
+	client := New(HTTPClient(&http.Client{Transport: mt}))
+	ctx := context.Background()
+
+	sub := 4
+	got, err := client.Portfolio.GetRestingOrderTotalValue(ctx, &types.GetRestingOrderTotalValueOpts{Subaccount: &sub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalRestingOrderValue != 125000 {
+		t.Errorf("TotalRestingOrderValue: %d", got.TotalRestingOrderValue)
+	}
+	if mt.req == nil || mt.req.URL.Path != "/trade-api/v2/portfolio/summary/total_resting_order_value" {
+		t.Fatalf("path: %v", mt.req)
+	}
+	if mt.req.URL.Query().Get("subaccount") != "4" {
+		t.Fatalf("query: %v", mt.req.URL.Query())
+	}
+}
+
+func TestMarkets_All_FollowsCursor(t *testing.T) {
+	pages := map[string]types.GetMarketsResponse{
+		"":   {Markets: []types.Market{{Ticker: "A"}, {Ticker: "B"}}, Cursor: "p2"},
+		"p2": {Markets: []types.Market{{Ticker: "C"}}, Cursor: ""},
+	}
+	var cursors []string
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		c := req.URL.Query().Get("cursor")
+		cursors = append(cursors, c)
+		return jsonResponse(req, pages[c]), nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+	ctx := context.Background()
+
+	var got []string
+	for m, err := range client.Markets.All(ctx, &types.GetMarketsOpts{Status: types.MarketStatusOpen}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, m.Ticker)
+	}
+	if strings.Join(got, ",") != "A,B,C" || len(cursors) != 2 {
+		t.Fatalf("got %v cursors %v", got, cursors)
+	}
+}
+
+func TestMarkets_All_RangesTwice(t *testing.T) {
+	pages := map[string]types.GetMarketsResponse{
+		"":   {Markets: []types.Market{{Ticker: "A"}}, Cursor: "p2"},
+		"p2": {Markets: []types.Market{{Ticker: "B"}}, Cursor: ""},
+	}
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, pages[req.URL.Query().Get("cursor")]), nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+	seq := client.Markets.All(context.Background(), nil)
+
+	for i := 0; i < 2; i++ {
+		var got []string
+		for m, err := range seq {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, m.Ticker)
+		}
+		if strings.Join(got, ",") != "A,B" {
+			t.Fatalf("pass %d: got %v", i+1, got)
+		}
+	}
+}
+
+func TestPortfolio_AllFills_StopsEarlyAndSurfacesErrors(t *testing.T) {
+	calls := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if req.URL.Query().Get("cursor") == "bad" {
+			resp := jsonResponse(req, map[string]string{"message": "bad cursor"})
+			resp.StatusCode = 400
+			return resp, nil
+		}
+		return jsonResponse(req, types.GetFillsResponse{Fills: []types.Fill{{FillID: "f1"}, {FillID: "f2"}}, Cursor: "bad"}), nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+	ctx := context.Background()
+
+	for range client.Portfolio.AllFills(ctx, nil) {
+		break
+	}
+	if calls != 1 {
+		t.Fatalf("early break: %d calls", calls)
+	}
+
+	var n int
+	var lastErr error
+	for _, err := range client.Portfolio.AllFills(ctx, nil) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		n++
+	}
+	var apiErr *APIError
+	if n != 2 || !errors.As(lastErr, &apiErr) || apiErr.StatusCode != 400 {
+		t.Fatalf("n=%d err=%v", n, lastErr)
+	}
+}
+
+func TestOrders_All_ContextCanceled(t *testing.T) {
+	client := New(HTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("unexpected request")
+		return nil, nil
+	})}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, err := range client.Orders.All(ctx, nil) {
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("err: %v", err)
+		}
+	}
+}
+
+func TestPortfolio_FillsInRange_SplitsAtCutoff(t *testing.T) {
+	// cutoff is 1000 on the first read and 2000 after the live pass
+	cutoffs := []string{"1970-01-01T00:16:40Z", "1970-01-01T00:33:20Z"}
+	ts := func(v int64) *int64 { return &v }
+	var historicalMin, historicalMax []string
+	var liveMin string
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/historical/cutoff"):
+			c := cutoffs[0]
+			if len(cutoffs) > 1 {
+				cutoffs = cutoffs[1:]
+			}
+			return jsonResponse(req, types.GetHistoricalCutoffResponse{TradesCreatedTs: c}), nil
+		case strings.HasSuffix(req.URL.Path, "/historical/fills"):
+			historicalMin = append(historicalMin, q.Get("min_ts"))
+			historicalMax = append(historicalMax, q.Get("max_ts"))
+			fills := []types.Fill{{FillID: "old", Ts: ts(100)}, {FillID: "early", Ts: ts(900)}, {FillID: "edge", Ts: ts(1000)}}
+			if q.Get("max_ts") == "2000" {
+				fills = append(fills, types.Fill{FillID: "moved", Ts: ts(1500)})
+			}
+			return jsonResponse(req, types.GetFillsResponse{Fills: fills}), nil
+		case strings.HasSuffix(req.URL.Path, "/portfolio/fills"):
+			liveMin = q.Get("min_ts")
+			return jsonResponse(req, types.GetFillsResponse{Fills: []types.Fill{{FillID: "edge", Ts: ts(1000)}, {FillID: "new", Ts: ts(1200)}}}), nil
+		}
+		t.Fatalf("unexpected path %s", req.URL.Path)
+		return nil, nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+
+	var got []string
+	for f, err := range client.Portfolio.FillsInRange(context.Background(), &types.HistoryRangeOpts{MinTs: ts(500)}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, f.FillID)
+	}
+	if strings.Join(got, ",") != "early,edge,new,moved" {
+		t.Fatalf("got %v", got)
+	}
+	if strings.Join(historicalMax, ",") != "1000,2000" || liveMin != "1000" {
+		t.Fatalf("historical max_ts %v live min_ts %q", historicalMax, liveMin)
+	}
+	if strings.Join(historicalMin, ",") != "500,1000" {
+		t.Fatalf("historical min_ts %v", historicalMin)
+	}
+}
+
+func TestPortfolio_OrdersInRange_RestingBeforeCutoff(t *testing.T) {
+	at := func(sec int64) *string {
+		v := time.Unix(sec, 0).UTC().Format(time.RFC3339)
+		return &v
+	}
+	var liveQuery url.Values
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/historical/cutoff"):
+			return jsonResponse(req, types.GetHistoricalCutoffResponse{OrdersUpdatedTs: "1970-01-01T00:16:40Z"}), nil
+		case strings.HasSuffix(req.URL.Path, "/historical/orders"):
+			if q.Get("min_ts") != "150" || q.Get("max_ts") != "950" {
+				t.Errorf("historical query %v", q)
+			}
+			return jsonResponse(req, types.GetOrdersResponse{Orders: []types.Order{
+				{OrderID: "canceled", Status: "canceled", CreatedTime: at(400), LastUpdateTime: at(900)},
+			}}), nil
+		case strings.HasSuffix(req.URL.Path, "/portfolio/orders"):
+			liveQuery = q
+			// min_ts filters on creation time, as the live endpoint does.
+			var orders []types.Order
+			for _, o := range []types.Order{
+				{OrderID: "resting", Status: "resting", CreatedTime: at(200), LastUpdateTime: at(300)},
+				{OrderID: "filled", Status: "executed", CreatedTime: at(600), LastUpdateTime: at(1200)},
+				{OrderID: "too-old", Status: "resting", CreatedTime: at(100), LastUpdateTime: at(100)},
+				{OrderID: "too-new", Status: "resting", CreatedTime: at(1100), LastUpdateTime: at(1100)},
+			} {
+				created, _ := parseTsPtr(o.CreatedTime)
+				if min, err := strconv.ParseInt(q.Get("min_ts"), 10, 64); err == nil && created < min {
+					continue
+				}
+				orders = append(orders, o)
+			}
+			return jsonResponse(req, types.GetOrdersResponse{Orders: orders}), nil
+		}
+		t.Fatalf("unexpected path %s", req.URL.Path)
+		return nil, nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+
+	min, max := int64(150), int64(950)
+	var got []string
+	for o, err := range client.Portfolio.OrdersInRange(context.Background(), &types.HistoryRangeOpts{MinTs: &min, MaxTs: &max}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, o.OrderID)
+	}
+	if strings.Join(got, ",") != "canceled,resting,filled" {
+		t.Fatalf("got %v", got)
+	}
+	if liveQuery == nil || liveQuery.Get("min_ts") != "150" {
+		t.Fatalf("live query %v", liveQuery)
+	}
+}
+
+func TestMarkets_SettledMarketsInRange_RequiresFilter(t *testing.T) {
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected request %s", req.URL)
+		return jsonResponse(req, struct{}{}), nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+	var errs []error
+	for _, err := range client.Markets.SettledMarketsInRange(context.Background(), nil) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] == nil || !strings.Contains(errs[0].Error(), "required") {
+		t.Fatalf("errs: %v", errs)
+	}
+}
+
+func TestMarkets_TradesInRange_LiveOnly(t *testing.T) {
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/historical/cutoff"):
+			return jsonResponse(req, types.GetHistoricalCutoffResponse{TradesCreatedTs: "1970-01-01T00:16:40Z"}), nil
+		case strings.HasSuffix(req.URL.Path, "/markets/trades"):
+			if req.URL.Query().Get("min_ts") != "1500" {
+				t.Errorf("min_ts %q", req.URL.Query().Get("min_ts"))
+			}
+			return jsonResponse(req, types.GetTradesResponse{Trades: []types.Trade{{TradeID: "t1", CreatedTime: "1970-01-01T00:30:00Z"}}}), nil
+		}
+		t.Fatalf("unexpected path %s", req.URL.Path)
+		return nil, nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+	min := int64(1500)
+	var n int
+	for _, err := range client.Markets.TradesInRange(context.Background(), &types.HistoryRangeOpts{MinTs: &min}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("n=%d", n)
+	}
+}
+
+func TestMarkets_BackfillTrades_OrderedDedupAndCheckpoint(t *testing.T) {
+	at := func(sec int64) string { return time.Unix(sec, 0).UTC().Format(time.RFC3339) }
+	var mu sync.Mutex
+	var windows []string
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		mu.Lock()
+		windows = append(windows, q.Get("min_ts")+"-"+q.Get("max_ts"))
+		mu.Unlock()
+		var trades []types.Trade
+		switch q.Get("min_ts") {
+		case "100":
+			// newest first, as the API returns them
+			trades = []types.Trade{{TradeID: "b", CreatedTime: at(150)}, {TradeID: "a", CreatedTime: at(120)}}
+		case "200":
+			trades = []types.Trade{{TradeID: "c", CreatedTime: at(250)}, {TradeID: "b", CreatedTime: at(150)}}
+		case "300":
+			trades = []types.Trade{{TradeID: "d", CreatedTime: at(300)}}
+		}
+		return jsonResponse(req, types.GetTradesResponse{Trades: trades}), nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+
+	var checkpoints []int64
+	opts := &types.BackfillOpts{
+		MinTs:             100,
+		MaxTs:             349,
+		Window:            100 * time.Second,
+		Parallelism:       2,
+		RequestsPerSecond: 1000,
+		OnCheckpoint: func(cp types.BackfillCheckpoint) error {
+			checkpoints = append(checkpoints, cp.NextTs)
+			return nil
+		},
+	}
+	var got []string
+	for tr, err := range client.Markets.BackfillTrades(context.Background(), opts) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tr.TradeID)
+	}
+	if strings.Join(got, ",") != "a,b,c,d" {
+		t.Fatalf("got %v", got)
+	}
+	if fmt.Sprint(checkpoints) != "[200 300 350]" || len(windows) != 3 {
+		t.Fatalf("checkpoints %v windows %v", checkpoints, windows)
+	}
+
+	windows = nil
+	opts.Resume = &types.BackfillCheckpoint{NextTs: 300}
+	opts.OnCheckpoint = nil
+	got = nil
+	for tr, err := range client.Markets.BackfillTrades(context.Background(), opts) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tr.TradeID)
+	}
+	if strings.Join(got, ",") != "d" || strings.Join(windows, ",") != "300-349" {
+		t.Fatalf("resume got %v windows %v", got, windows)
+	}
+}
+
+func TestMarkets_BackfillTrades_WindowsAreLazy(t *testing.T) {
+	var requests atomic.Int64
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		ts, _ := strconv.ParseInt(req.URL.Query().Get("min_ts"), 10, 64)
+		trade := types.Trade{TradeID: fmt.Sprint(ts), CreatedTime: time.Unix(ts, 0).UTC().Format(time.RFC3339)}
+		return jsonResponse(req, types.GetTradesResponse{Trades: []types.Trade{trade}}), nil
+	})
+	client := New(HTTPClient(&http.Client{Transport: rt}))
+
+	// Ten years of one-second windows; only a few may be generated.
+	opts := &types.BackfillOpts{MinTs: 0, MaxTs: 10 * 365 * 86400, Window: time.Second, Parallelism: 3, RequestsPerSecond: 1e6}
+	n := 0
+	for _, err := range client.Markets.BackfillTrades(context.Background(), opts) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 5 {
+			break
+		}
+	}
+	if r := requests.Load(); r > 5+3 {
+		t.Fatalf("%d requests for 5 windows", r)
+	}
+}
@@ -0,0 +1,141 @@
+package oddrip
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/url"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+type CommunicationsService struct {
+	client *Client
+}
+
+func (s *CommunicationsService) GetID(ctx context.Context) (*types.GetCommunicationsIDResponse, error) {
+	var out types.GetCommunicationsIDResponse
+	if err := s.client.get(ctx, joinPath("communications", "id"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *CommunicationsService) ListRFQs(ctx context.Context, opts *types.GetRFQsOpts) (*types.GetRFQsResponse, error) {
+	v := url.Values{}
+	if opts != nil {
+		encodeQueryInt(v, "limit", opts.Limit)
+		encodeQuery(v, "cursor", opts.Cursor)
+		encodeQuery(v, "event_ticker", opts.EventTicker)
+		encodeQuery(v, "market_ticker", opts.MarketTicker)
+		encodeQueryInt(v, "subaccount", opts.Subaccount)
+		encodeQuery(v, "status", opts.Status)
+		encodeQuery(v, "creator_user_id", opts.CreatorUserID)
+	}
+	var out types.GetRFQsResponse
+	if err := s.client.get(ctx, joinPath("communications", "rfqs"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *CommunicationsService) CreateRFQ(ctx context.Context, req *types.CreateRFQRequest) (*types.CreateRFQResponse, error) {
+	var out types.CreateRFQResponse
+	if err := s.client.post(ctx, joinPath("communications", "rfqs"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *CommunicationsService) GetRFQ(ctx context.Context, rfqID string) (*types.GetRFQResponse, error) {
+	var out types.GetRFQResponse
+	if err := s.client.get(ctx, joinPath("communications", "rfqs", rfqID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *CommunicationsService) DeleteRFQ(ctx context.Context, rfqID string) error {
+	return s.client.delete(ctx, joinPath("communications", "rfqs", rfqID), nil, nil, nil)
+}
+
+func (s *CommunicationsService) ListQuotes(ctx context.Context, opts *types.GetQuotesOpts) (*types.GetQuotesResponse, error) {
+	v := url.Values{}
+	if opts != nil {
+		encodeQueryInt(v, "limit", opts.Limit)
+		encodeQuery(v, "cursor", opts.Cursor)
+		encodeQuery(v, "event_ticker", opts.EventTicker)
+		encodeQuery(v, "market_ticker", opts.MarketTicker)
+		encodeQuery(v, "status", opts.Status)
+		encodeQuery(v, "quote_creator_user_id", opts.QuoteCreatorUserID)
+		encodeQuery(v, "rfq_creator_user_id", opts.RFQCreatorUserID)
+		encodeQuery(v, "rfq_creator_subtrader_id", opts.RFQCreatorSubtraderID)
+		encodeQuery(v, "rfq_id", opts.RFQID)
+	}
+	var out types.GetQuotesResponse
+	if err := s.client.get(ctx, joinPath("communications", "quotes"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *CommunicationsService) CreateQuote(ctx context.Context, req *types.CreateQuoteRequest) (*types.CreateQuoteResponse, error) {
+	var out types.CreateQuoteResponse
+	if err := s.client.post(ctx, joinPath("communications", "quotes"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *CommunicationsService) GetQuote(ctx context.Context, quoteID string) (*types.GetQuoteResponse, error) {
+	var out types.GetQuoteResponse
+	if err := s.client.get(ctx, joinPath("communications", "quotes", quoteID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *CommunicationsService) DeleteQuote(ctx context.Context, quoteID string) error {
+	return s.client.delete(ctx, joinPath("communications", "quotes", quoteID), nil, nil, nil)
+}
+
+func (s *CommunicationsService) AcceptQuote(ctx context.Context, quoteID string, req *types.AcceptQuoteRequest) error {
+	if req == nil || (req.AcceptedSide != types.OrderSideYes && req.AcceptedSide != types.OrderSideNo) {
+		return errors.New("accepted_side must be yes or no")
+	}
+	return s.client.put(ctx, joinPath("communications", "quotes", quoteID, "accept"), nil, req, nil)
+}
+
+func (s *CommunicationsService) ConfirmQuote(ctx context.Context, quoteID string) error {
+	return s.client.put(ctx, joinPath("communications", "quotes", quoteID, "confirm"), nil, struct{}{}, nil)
+}
+
+func (s *CommunicationsService) AllRFQs(ctx context.Context, opts *types.GetRFQsOpts) iter.Seq2[types.RFQ, error] {
+	var o types.GetRFQsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.RFQ, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListRFQs(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.RFQs, resp.Cursor, nil
+	})
+}
+
+func (s *CommunicationsService) AllQuotes(ctx context.Context, opts *types.GetQuotesOpts) iter.Seq2[types.Quote, error] {
+	var o types.GetQuotesOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Quote, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListQuotes(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Quotes, resp.Cursor, nil
+	})
+}
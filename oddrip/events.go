@@ -2,6 +2,9 @@ package oddrip
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"net/url"
 
 	"github.com/UTXOnly/oddrip/oddrip/types"
@@ -65,3 +68,83 @@ func (s *EventsService) GetMetadata(ctx context.Context, eventTicker string) (*t
 	}
 	return &out, nil
 }
+
+func (s *EventsService) GetCandlesticks(ctx context.Context, seriesTicker, eventTicker string, opts *types.GetEventCandlesticksOpts) (*types.GetEventCandlesticksResponse, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts required")
+	}
+	if err := validatePeriodInterval(opts.PeriodInterval); err != nil {
+		return nil, err
+	}
+	v := url.Values{}
+	v.Set("start_ts", fmt.Sprintf("%d", opts.StartTs))
+	v.Set("end_ts", fmt.Sprintf("%d", opts.EndTs))
+	v.Set("period_interval", fmt.Sprintf("%d", opts.PeriodInterval))
+	var out types.GetEventCandlesticksResponse
+	if err := s.client.get(ctx, joinPath("series", seriesTicker, "events", eventTicker, "candlesticks"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *EventsService) GetForecastPercentileHistory(ctx context.Context, seriesTicker, eventTicker string, opts *types.GetEventForecastPercentileHistoryOpts) (*types.GetEventForecastPercentileHistoryResponse, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts required")
+	}
+	if len(opts.Percentiles) == 0 || len(opts.Percentiles) > 10 {
+		return nil, errors.New("between 1 and 10 percentiles required")
+	}
+	if opts.PeriodInterval == nil {
+		return nil, errors.New("period_interval required")
+	}
+	switch *opts.PeriodInterval {
+	case types.PeriodInterval5Sec, types.PeriodInterval1Min, types.PeriodInterval1Hour, types.PeriodInterval1Day:
+	default:
+		return nil, fmt.Errorf("period_interval must be 0, 1, 60, or 1440")
+	}
+	v := url.Values{}
+	for _, p := range opts.Percentiles {
+		if p < 0 || p > 10000 {
+			return nil, fmt.Errorf("percentile %d out of range 0-10000", p)
+		}
+		v.Add("percentiles", fmt.Sprintf("%d", p))
+	}
+	v.Set("start_ts", fmt.Sprintf("%d", opts.StartTs))
+	v.Set("end_ts", fmt.Sprintf("%d", opts.EndTs))
+	v.Set("period_interval", fmt.Sprintf("%d", *opts.PeriodInterval))
+	var out types.GetEventForecastPercentileHistoryResponse
+	if err := s.client.get(ctx, joinPath("series", seriesTicker, "events", eventTicker, "forecast_percentile_history"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *EventsService) All(ctx context.Context, opts *types.GetEventsOpts) iter.Seq2[types.EventData, error] {
+	var o types.GetEventsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.EventData, string, error) {
+		o.Cursor = cursor
+		resp, err := s.List(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Events, resp.Cursor, nil
+	})
+}
+
+func (s *EventsService) AllMultivariate(ctx context.Context, opts *types.GetMultivariateEventsOpts) iter.Seq2[types.EventData, error] {
+	var o types.GetMultivariateEventsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.EventData, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListMultivariate(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Events, resp.Cursor, nil
+	})
+}
@@ -0,0 +1,83 @@
+package oddrip
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/url"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+type FCMService struct {
+	client *Client
+}
+
+func (s *FCMService) ListOrders(ctx context.Context, opts *types.GetFCMOrdersOpts) (*types.GetOrdersResponse, error) {
+	if opts == nil || opts.SubtraderID == "" {
+		return nil, errors.New("subtrader_id required")
+	}
+	v := url.Values{}
+	encodeQuery(v, "subtrader_id", opts.SubtraderID)
+	encodeQuery(v, "cursor", opts.Cursor)
+	encodeQuery(v, "event_ticker", opts.EventTicker)
+	encodeQuery(v, "ticker", opts.Ticker)
+	encodeQueryInt64(v, "min_ts", opts.MinTs)
+	encodeQueryInt64(v, "max_ts", opts.MaxTs)
+	encodeQuery(v, "status", opts.Status)
+	encodeQueryInt(v, "limit", opts.Limit)
+	var out types.GetOrdersResponse
+	if err := s.client.get(ctx, joinPath("fcm", "orders"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *FCMService) ListPositions(ctx context.Context, opts *types.GetFCMPositionsOpts) (*types.GetPositionsResponse, error) {
+	if opts == nil || opts.SubtraderID == "" {
+		return nil, errors.New("subtrader_id required")
+	}
+	v := url.Values{}
+	encodeQuery(v, "subtrader_id", opts.SubtraderID)
+	encodeQuery(v, "ticker", opts.Ticker)
+	encodeQuery(v, "event_ticker", opts.EventTicker)
+	encodeQuery(v, "count_filter", opts.CountFilter)
+	encodeQuery(v, "settlement_status", opts.SettlementStatus)
+	encodeQueryInt(v, "limit", opts.Limit)
+	encodeQuery(v, "cursor", opts.Cursor)
+	var out types.GetPositionsResponse
+	if err := s.client.get(ctx, joinPath("fcm", "positions"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *FCMService) AllOrders(ctx context.Context, opts *types.GetFCMOrdersOpts) iter.Seq2[types.Order, error] {
+	var o types.GetFCMOrdersOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Order, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListOrders(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Orders, resp.Cursor, nil
+	})
+}
+
+func (s *FCMService) AllPositions(ctx context.Context, opts *types.GetFCMPositionsOpts) iter.Seq2[types.MarketPosition, error] {
+	var o types.GetFCMPositionsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.MarketPosition, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListPositions(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.MarketPositions, resp.Cursor, nil
+	})
+}
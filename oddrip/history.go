@@ -0,0 +1,246 @@
+package oddrip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+// cutoffSplit describes a resource whose records move from a live endpoint to
+// a /historical endpoint once they are older than one of the exchange's
+// historical cutoffs. ts places a record in the requested range; moved, when
+// set, is the time the cutoff applies to if that is a different field.
+type cutoffSplit[T any] struct {
+	cutoff     func(*types.GetHistoricalCutoffResponse) string
+	historical func(minTs *int64, maxTs int64) iter.Seq2[T, error]
+	live       func(minTs *int64) iter.Seq2[T, error]
+	id         func(T) string
+	ts         func(T) (int64, bool)
+	moved      func(T) (int64, bool)
+}
+
+// acrossCutoff reads [minTs, maxTs] from the historical endpoint up to the
+// cutoff and from the live endpoint after it, dropping records that show up on
+// both sides. The cutoff is re-read after the live pass; if it advanced, records
+// that migrated to the archive mid-iteration are picked up from there.
+func acrossCutoff[T any](ctx context.Context, c *Client, minTs, maxTs *int64, sp cutoffSplit[T]) iter.Seq2[T, error] {
+	moved := sp.moved
+	if moved == nil {
+		moved = sp.ts
+	}
+	return func(yield func(T, error) bool) {
+		var zero T
+		c0, err := historicalCutoff(ctx, c, sp.cutoff)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		seen := make(map[string]struct{})
+		emit := func(seq iter.Seq2[T, error], live, migratedOnly bool) bool {
+			for item, err := range seq {
+				if err != nil {
+					yield(zero, err)
+					return false
+				}
+				ts, ok := sp.ts(item)
+				if ok && ((minTs != nil && ts < *minTs) || (maxTs != nil && ts > *maxTs)) {
+					continue
+				}
+				mts, mok := moved(item)
+				if migratedOnly && (!mok || mts < c0) {
+					continue
+				}
+				if live || !mok || mts >= c0 {
+					id := sp.id(item)
+					if _, dup := seen[id]; dup {
+						continue
+					}
+					seen[id] = struct{}{}
+				}
+				if !yield(item, nil) {
+					return false
+				}
+			}
+			return true
+		}
+
+		if minTs == nil || *minTs < c0 {
+			if !emit(sp.historical(minTs, capTs(c0, maxTs)), false, false) {
+				return
+			}
+		}
+		// A record placed before the cutoff by ts can still be live when the
+		// cutoff applies to a later field, e.g. a resting order.
+		if sp.moved == nil && maxTs != nil && *maxTs < c0 {
+			return
+		}
+		liveMin := minTs
+		if sp.moved == nil && (minTs == nil || *minTs < c0) {
+			liveMin = &c0
+		}
+		if !emit(sp.live(liveMin), true, false) {
+			return
+		}
+		c1, err := historicalCutoff(ctx, c, sp.cutoff)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		if c1 > c0 {
+			lower := minTs
+			if sp.moved == nil {
+				lower = liveMin
+			}
+			emit(sp.historical(lower, capTs(c1, maxTs)), false, true)
+		}
+	}
+}
+
+func historicalCutoff(ctx context.Context, c *Client, field func(*types.GetHistoricalCutoffResponse) string) (int64, error) {
+	resp, err := c.Exchange.GetHistoricalCutoff(ctx)
+	if err != nil {
+		return 0, err
+	}
+	ts, ok := parseTs(field(resp))
+	if !ok {
+		return 0, fmt.Errorf("historical cutoff: invalid timestamp %q", field(resp))
+	}
+	return ts, nil
+}
+
+func capTs(ts int64, max *int64) int64 {
+	if max != nil && *max < ts {
+		return *max
+	}
+	return ts
+}
+
+func parseTs(s string) (int64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Unix(), true
+}
+
+func parseTsPtr(s *string) (int64, bool) {
+	if s == nil {
+		return 0, false
+	}
+	return parseTs(*s)
+}
+
+// FillsInRange yields fills with timestamps in [opts.MinTs, opts.MaxTs] from
+// /historical/fills and /portfolio/fills, split at the trades_created_ts cutoff.
+func (s *PortfolioService) FillsInRange(ctx context.Context, opts *types.HistoryRangeOpts) iter.Seq2[types.Fill, error] {
+	var o types.HistoryRangeOpts
+	if opts != nil {
+		o = *opts
+	}
+	return acrossCutoff(ctx, s.client, o.MinTs, o.MaxTs, cutoffSplit[types.Fill]{
+		cutoff: func(r *types.GetHistoricalCutoffResponse) string { return r.TradesCreatedTs },
+		historical: func(minTs *int64, maxTs int64) iter.Seq2[types.Fill, error] {
+			return s.AllHistoricalFills(ctx, &types.GetHistoricalArchiveOpts{Ticker: o.Ticker, MinTs: minTs, MaxTs: &maxTs, Limit: o.Limit})
+		},
+		live: func(minTs *int64) iter.Seq2[types.Fill, error] {
+			return s.AllFills(ctx, &types.GetFillsOpts{Ticker: o.Ticker, MinTs: minTs, MaxTs: o.MaxTs, Limit: o.Limit})
+		},
+		id: func(f types.Fill) string { return f.FillID },
+		ts: func(f types.Fill) (int64, bool) {
+			if f.Ts != nil {
+				return *f.Ts, true
+			}
+			return parseTs(f.CreatedTime)
+		},
+	})
+}
+
+// OrdersInRange yields orders created in [opts.MinTs, opts.MaxTs] from
+// /historical/orders and /portfolio/orders, split at the orders_updated_ts
+// cutoff. Resting orders stay on /portfolio/orders however old they are, so the
+// live pass is not bounded by the cutoff.
+func (s *PortfolioService) OrdersInRange(ctx context.Context, opts *types.HistoryRangeOpts) iter.Seq2[types.Order, error] {
+	var o types.HistoryRangeOpts
+	if opts != nil {
+		o = *opts
+	}
+	return acrossCutoff(ctx, s.client, o.MinTs, o.MaxTs, cutoffSplit[types.Order]{
+		cutoff: func(r *types.GetHistoricalCutoffResponse) string { return r.OrdersUpdatedTs },
+		historical: func(minTs *int64, maxTs int64) iter.Seq2[types.Order, error] {
+			return s.AllHistoricalOrders(ctx, &types.GetHistoricalArchiveOpts{Ticker: o.Ticker, MinTs: minTs, MaxTs: &maxTs, Limit: o.Limit})
+		},
+		live: func(minTs *int64) iter.Seq2[types.Order, error] {
+			return s.client.Orders.All(ctx, &types.GetOrdersOpts{Ticker: o.Ticker, MinTs: minTs, MaxTs: o.MaxTs, Limit: o.Limit})
+		},
+		id: func(ord types.Order) string { return ord.OrderID },
+		ts: func(ord types.Order) (int64, bool) { return parseTsPtr(ord.CreatedTime) },
+		moved: func(ord types.Order) (int64, bool) {
+			if ts, ok := parseTsPtr(ord.LastUpdateTime); ok {
+				return ts, true
+			}
+			return parseTsPtr(ord.CreatedTime)
+		},
+	})
+}
+
+// TradesInRange yields public trades from /historical/trades and
+// /markets/trades, split at the trades_created_ts cutoff.
+func (s *MarketsService) TradesInRange(ctx context.Context, opts *types.HistoryRangeOpts) iter.Seq2[types.Trade, error] {
+	var o types.HistoryRangeOpts
+	if opts != nil {
+		o = *opts
+	}
+	return acrossCutoff(ctx, s.client, o.MinTs, o.MaxTs, cutoffSplit[types.Trade]{
+		cutoff: func(r *types.GetHistoricalCutoffResponse) string { return r.TradesCreatedTs },
+		historical: func(minTs *int64, maxTs int64) iter.Seq2[types.Trade, error] {
+			return s.AllHistoricalTrades(ctx, &types.GetTradesOpts{Ticker: o.Ticker, MinTs: minTs, MaxTs: &maxTs, Limit: o.Limit})
+		},
+		live: func(minTs *int64) iter.Seq2[types.Trade, error] {
+			return s.AllTrades(ctx, &types.GetTradesOpts{Ticker: o.Ticker, MinTs: minTs, MaxTs: o.MaxTs, Limit: o.Limit})
+		},
+		id: func(t types.Trade) string { return t.TradeID },
+		ts: func(t types.Trade) (int64, bool) { return parseTs(t.CreatedTime) },
+	})
+}
+
+// SettledMarketsInRange yields markets that settled in
+// [opts.MinSettledTs, opts.MaxSettledTs] from /historical/markets and /markets,
+// split at the market_settled_ts cutoff. The historical endpoint has no time
+// filter, so EventTicker or Tickers is required to avoid scanning the whole
+// archive.
+func (s *MarketsService) SettledMarketsInRange(ctx context.Context, opts *types.SettledMarketsRangeOpts) iter.Seq2[types.Market, error] {
+	var o types.SettledMarketsRangeOpts
+	if opts != nil {
+		o = *opts
+	}
+	if o.EventTicker == "" && o.Tickers == "" {
+		return func(yield func(types.Market, error) bool) {
+			yield(types.Market{}, errors.New("event_ticker or tickers required"))
+		}
+	}
+	return acrossCutoff(ctx, s.client, o.MinSettledTs, o.MaxSettledTs, cutoffSplit[types.Market]{
+		cutoff: func(r *types.GetHistoricalCutoffResponse) string { return r.MarketSettledTs },
+		historical: func(*int64, int64) iter.Seq2[types.Market, error] {
+			return s.AllHistorical(ctx, &types.GetHistoricalMarketsOpts{Tickers: o.Tickers, EventTicker: o.EventTicker, MveFilter: o.MveFilter, Limit: o.Limit})
+		},
+		live: func(minTs *int64) iter.Seq2[types.Market, error] {
+			return s.All(ctx, &types.GetMarketsOpts{
+				Tickers:      o.Tickers,
+				EventTicker:  o.EventTicker,
+				MveFilter:    o.MveFilter,
+				MinSettledTs: minTs,
+				MaxSettledTs: o.MaxSettledTs,
+				Limit:        o.Limit,
+			})
+		},
+		id: func(m types.Market) string { return m.Ticker },
+		ts: func(m types.Market) (int64, bool) { return parseTsPtr(m.SettlementTs) },
+	})
+}
@@ -0,0 +1,43 @@
+package oddrip
+
+import (
+	"context"
+	"iter"
+	"net/url"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+type IncentiveProgramsService struct {
+	client *Client
+}
+
+func (s *IncentiveProgramsService) List(ctx context.Context, opts *types.GetIncentiveProgramsOpts) (*types.GetIncentiveProgramsResponse, error) {
+	v := url.Values{}
+	if opts != nil {
+		encodeQuery(v, "status", opts.Status)
+		encodeQuery(v, "type", opts.Type)
+		encodeQueryInt(v, "limit", opts.Limit)
+		encodeQuery(v, "cursor", opts.Cursor)
+	}
+	var out types.GetIncentiveProgramsResponse
+	if err := s.client.get(ctx, joinPath("incentive_programs"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *IncentiveProgramsService) All(ctx context.Context, opts *types.GetIncentiveProgramsOpts) iter.Seq2[types.IncentiveProgram, error] {
+	var o types.GetIncentiveProgramsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.IncentiveProgram, string, error) {
+		o.Cursor = cursor
+		resp, err := s.List(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.IncentivePrograms, resp.NextCursor, nil
+	})
+}
@@ -2,12 +2,20 @@ package oddrip
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"net/url"
+	"strings"
 
 	"github.com/UTXOnly/oddrip/oddrip/types"
 )
 
+const (
+	maxBatchCandlestickTickers = 100
+	maxBatchCandlesticks       = 10000
+)
+
 type MarketsService struct {
 	client *Client
 }
@@ -117,10 +125,8 @@ func (s *MarketsService) GetHistoricalCandlesticks(ctx context.Context, ticker s
 	if opts == nil {
 		return nil, fmt.Errorf("opts required")
 	}
-	switch opts.PeriodInterval {
-	case 1, 60, 1440:
-	default:
-		return nil, fmt.Errorf("period_interval must be 1, 60, or 1440")
+	if err := validatePeriodInterval(opts.PeriodInterval); err != nil {
+		return nil, err
 	}
 	v := url.Values{}
 	v.Set("start_ts", fmt.Sprintf("%d", opts.StartTs))
@@ -132,3 +138,172 @@ func (s *MarketsService) GetHistoricalCandlesticks(ctx context.Context, ticker s
 	}
 	return &out, nil
 }
+
+func (s *MarketsService) BatchGetCandlesticks(ctx context.Context, opts *types.BatchGetMarketCandlesticksOpts) (*types.BatchGetMarketCandlesticksResponse, error) {
+	if opts == nil || len(opts.MarketTickers) == 0 {
+		return nil, errors.New("market_tickers required")
+	}
+	if len(opts.MarketTickers) > maxBatchCandlestickTickers {
+		return nil, fmt.Errorf("at most %d market_tickers per request", maxBatchCandlestickTickers)
+	}
+	if opts.PeriodInterval < 1 {
+		return nil, errors.New("period_interval must be positive")
+	}
+	v := url.Values{}
+	v.Set("market_tickers", strings.Join(opts.MarketTickers, ","))
+	v.Set("start_ts", fmt.Sprintf("%d", opts.StartTs))
+	v.Set("end_ts", fmt.Sprintf("%d", opts.EndTs))
+	v.Set("period_interval", fmt.Sprintf("%d", opts.PeriodInterval))
+	encodeQueryBool(v, "include_latest_before_start", opts.IncludeLatestBeforeStart)
+	var out types.BatchGetMarketCandlesticksResponse
+	if err := s.client.get(ctx, joinPath("markets", "candlesticks"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCandlesticksByTicker calls BatchGetCandlesticks as many times as needed to
+// cover opts.MarketTickers, keeping each call under the per-request ticker and
+// candlestick caps, and returns the candles keyed by market ticker. A time range
+// too long for one call per ticker is split into consecutive ranges.
+func (s *MarketsService) GetCandlesticksByTicker(ctx context.Context, opts *types.BatchGetMarketCandlesticksOpts) (map[string][]types.MarketCandlestick, error) {
+	if opts == nil || len(opts.MarketTickers) == 0 {
+		return nil, errors.New("market_tickers required")
+	}
+	if opts.PeriodInterval < 1 {
+		return nil, errors.New("period_interval must be positive")
+	}
+	out := make(map[string][]types.MarketCandlestick, len(opts.MarketTickers))
+	for i, r := range candlestickRanges(opts.StartTs, opts.EndTs, opts.PeriodInterval) {
+		latest := opts.IncludeLatestBeforeStart
+		if i > 0 {
+			// The previous range already holds the candle before this start.
+			latest = nil
+		}
+		size := batchCandlestickChunkSize(r[0], r[1], opts.PeriodInterval, latest != nil && *latest)
+		for start := 0; start < len(opts.MarketTickers); start += size {
+			end := min(start+size, len(opts.MarketTickers))
+			chunk := *opts
+			chunk.MarketTickers = opts.MarketTickers[start:end]
+			chunk.StartTs, chunk.EndTs = r[0], r[1]
+			chunk.IncludeLatestBeforeStart = latest
+			resp, err := s.BatchGetCandlesticks(ctx, &chunk)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range resp.Markets {
+				out[m.MarketTicker] = append(out[m.MarketTicker], m.Candlesticks...)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (s *MarketsService) All(ctx context.Context, opts *types.GetMarketsOpts) iter.Seq2[types.Market, error] {
+	var o types.GetMarketsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Market, string, error) {
+		o.Cursor = cursor
+		resp, err := s.List(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Markets, resp.Cursor, nil
+	})
+}
+
+func (s *MarketsService) AllTrades(ctx context.Context, opts *types.GetTradesOpts) iter.Seq2[types.Trade, error] {
+	var o types.GetTradesOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Trade, string, error) {
+		o.Cursor = cursor
+		resp, err := s.GetTrades(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Trades, resp.Cursor, nil
+	})
+}
+
+func (s *MarketsService) AllHistorical(ctx context.Context, opts *types.GetHistoricalMarketsOpts) iter.Seq2[types.Market, error] {
+	var o types.GetHistoricalMarketsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Market, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListHistorical(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Markets, resp.Cursor, nil
+	})
+}
+
+func (s *MarketsService) AllHistoricalTrades(ctx context.Context, opts *types.GetTradesOpts) iter.Seq2[types.Trade, error] {
+	var o types.GetTradesOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Trade, string, error) {
+		o.Cursor = cursor
+		resp, err := s.GetHistoricalTrades(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Trades, resp.Cursor, nil
+	})
+}
+
+// candlesticksPerTicker counts the candles one ticker can return for
+// [startTs, endTs], plus the one include_latest_before_start adds.
+func candlesticksPerTicker(startTs, endTs int64, periodInterval int, latest bool) int64 {
+	n := int64(1)
+	if endTs > startTs {
+		n += (endTs - startTs) / (int64(periodInterval) * 60)
+	}
+	if latest {
+		n++
+	}
+	return n
+}
+
+// candlestickRanges splits [startTs, endTs] into consecutive inclusive ranges
+// of at most maxBatchCandlesticks candles per ticker, leaving room for the
+// include_latest_before_start candle.
+func candlestickRanges(startTs, endTs int64, periodInterval int) [][2]int64 {
+	step := int64(periodInterval) * 60
+	span := int64(maxBatchCandlesticks-2) * step
+	if endTs-startTs <= span {
+		return [][2]int64{{startTs, endTs}}
+	}
+	var out [][2]int64
+	for from := startTs; from <= endTs; from += span + 1 {
+		out = append(out, [2]int64{from, min(from+span, endTs)})
+	}
+	return out
+}
+
+func batchCandlestickChunkSize(startTs, endTs int64, periodInterval int, latest bool) int {
+	n := int64(maxBatchCandlesticks) / candlesticksPerTicker(startTs, endTs, periodInterval, latest)
+	if n > maxBatchCandlestickTickers {
+		n = maxBatchCandlestickTickers
+	}
+	if n < 1 {
+		n = 1
+	}
+	return int(n)
+}
+
+func validatePeriodInterval(periodInterval int) error {
+	switch periodInterval {
+	case types.PeriodInterval1Min, types.PeriodInterval1Hour, types.PeriodInterval1Day:
+		return nil
+	default:
+		return fmt.Errorf("period_interval must be 1, 60, or 1440")
+	}
+}
@@ -0,0 +1,93 @@
+package oddrip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+const (
+	defaultMilestonesLimit = 100
+	maxLiveDataBatch       = 100
+)
+
+type MilestonesService struct {
+	client *Client
+}
+
+func (s *MilestonesService) List(ctx context.Context, opts *types.GetMilestonesOpts) (*types.GetMilestonesResponse, error) {
+	v := url.Values{}
+	limit := defaultMilestonesLimit
+	if opts != nil {
+		if opts.Limit > 0 {
+			limit = opts.Limit
+		}
+		encodeQuery(v, "cursor", opts.Cursor)
+		encodeQuery(v, "minimum_start_date", opts.MinimumStartDate)
+		encodeQuery(v, "category", opts.Category)
+		encodeQuery(v, "competition", opts.Competition)
+		encodeQuery(v, "source_id", opts.SourceID)
+		encodeQuery(v, "type", opts.Type)
+		encodeQuery(v, "related_event_ticker", opts.RelatedEventTicker)
+		encodeQueryInt64(v, "min_updated_ts", opts.MinUpdatedTs)
+	}
+	v.Set("limit", fmt.Sprintf("%d", limit))
+	var out types.GetMilestonesResponse
+	if err := s.client.get(ctx, joinPath("milestones"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *MilestonesService) Get(ctx context.Context, milestoneID string) (*types.GetMilestoneResponse, error) {
+	var out types.GetMilestoneResponse
+	if err := s.client.get(ctx, joinPath("milestones", milestoneID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *MilestonesService) GetLiveData(ctx context.Context, liveDataType, milestoneID string) (*types.GetLiveDataResponse, error) {
+	var out types.GetLiveDataResponse
+	if err := s.client.get(ctx, joinPath("live_data", liveDataType, "milestone", milestoneID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *MilestonesService) GetLiveDataBatch(ctx context.Context, milestoneIDs []string) (*types.GetLiveDataBatchResponse, error) {
+	if len(milestoneIDs) == 0 {
+		return nil, errors.New("milestone_ids required")
+	}
+	if len(milestoneIDs) > maxLiveDataBatch {
+		return nil, fmt.Errorf("at most %d milestone_ids per request", maxLiveDataBatch)
+	}
+	v := url.Values{}
+	for _, id := range milestoneIDs {
+		v.Add("milestone_ids", id)
+	}
+	var out types.GetLiveDataBatchResponse
+	if err := s.client.get(ctx, joinPath("live_data", "batch"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *MilestonesService) All(ctx context.Context, opts *types.GetMilestonesOpts) iter.Seq2[types.Milestone, error] {
+	var o types.GetMilestonesOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Milestone, string, error) {
+		o.Cursor = cursor
+		resp, err := s.List(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Milestones, resp.Cursor, nil
+	})
+}
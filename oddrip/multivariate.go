@@ -0,0 +1,91 @@
+package oddrip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+type MultivariateCollectionsService struct {
+	client *Client
+}
+
+func (s *MultivariateCollectionsService) List(ctx context.Context, opts *types.GetMultivariateEventCollectionsOpts) (*types.GetMultivariateEventCollectionsResponse, error) {
+	v := url.Values{}
+	if opts != nil {
+		encodeQuery(v, "status", opts.Status)
+		encodeQuery(v, "associated_event_ticker", opts.AssociatedEventTicker)
+		encodeQuery(v, "series_ticker", opts.SeriesTicker)
+		encodeQueryInt(v, "limit", opts.Limit)
+		encodeQuery(v, "cursor", opts.Cursor)
+	}
+	var out types.GetMultivariateEventCollectionsResponse
+	if err := s.client.get(ctx, joinPath("multivariate_event_collections"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *MultivariateCollectionsService) Get(ctx context.Context, collectionTicker string) (*types.GetMultivariateEventCollectionResponse, error) {
+	var out types.GetMultivariateEventCollectionResponse
+	if err := s.client.get(ctx, joinPath("multivariate_event_collections", collectionTicker), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *MultivariateCollectionsService) CreateMarket(ctx context.Context, collectionTicker string, req *types.CreateMarketInMultivariateEventCollectionRequest) (*types.CreateMarketInMultivariateEventCollectionResponse, error) {
+	if req == nil || len(req.SelectedMarkets) == 0 {
+		return nil, errors.New("selected_markets required")
+	}
+	var out types.CreateMarketInMultivariateEventCollectionResponse
+	if err := s.client.post(ctx, joinPath("multivariate_event_collections", collectionTicker), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *MultivariateCollectionsService) LookupTickers(ctx context.Context, collectionTicker string, req *types.LookupTickersForMarketInMultivariateEventCollectionRequest) (*types.LookupTickersForMarketInMultivariateEventCollectionResponse, error) {
+	if req == nil || len(req.SelectedMarkets) == 0 {
+		return nil, errors.New("selected_markets required")
+	}
+	var out types.LookupTickersForMarketInMultivariateEventCollectionResponse
+	if err := s.client.put(ctx, joinPath("multivariate_event_collections", collectionTicker, "lookup"), nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *MultivariateCollectionsService) GetLookupHistory(ctx context.Context, collectionTicker string, lookbackSeconds int) (*types.GetMultivariateEventCollectionLookupHistoryResponse, error) {
+	switch lookbackSeconds {
+	case 10, 60, 300, 3600:
+	default:
+		return nil, fmt.Errorf("lookback_seconds must be 10, 60, 300, or 3600")
+	}
+	v := url.Values{}
+	v.Set("lookback_seconds", fmt.Sprintf("%d", lookbackSeconds))
+	var out types.GetMultivariateEventCollectionLookupHistoryResponse
+	if err := s.client.get(ctx, joinPath("multivariate_event_collections", collectionTicker, "lookup"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *MultivariateCollectionsService) All(ctx context.Context, opts *types.GetMultivariateEventCollectionsOpts) iter.Seq2[types.MultivariateEventCollection, error] {
+	var o types.GetMultivariateEventCollectionsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.MultivariateEventCollection, string, error) {
+		o.Cursor = cursor
+		resp, err := s.List(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.MultivariateContracts, resp.Cursor, nil
+	})
+}
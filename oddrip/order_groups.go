@@ -0,0 +1,64 @@
+package oddrip
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+type OrderGroupsService struct {
+	client *Client
+}
+
+func (s *OrderGroupsService) List(ctx context.Context, opts *types.GetOrderGroupsOpts) (*types.GetOrderGroupsResponse, error) {
+	v := url.Values{}
+	if opts != nil {
+		encodeQueryInt(v, "subaccount", opts.Subaccount)
+	}
+	var out types.GetOrderGroupsResponse
+	if err := s.client.get(ctx, joinPath("portfolio", "order_groups"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *OrderGroupsService) Create(ctx context.Context, req *types.CreateOrderGroupRequest) (*types.CreateOrderGroupResponse, error) {
+	var out types.CreateOrderGroupResponse
+	if err := s.client.post(ctx, joinPath("portfolio", "order_groups", "create"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *OrderGroupsService) Get(ctx context.Context, orderGroupID string, subaccount *int) (*types.GetOrderGroupResponse, error) {
+	v := url.Values{}
+	encodeQueryInt(v, "subaccount", subaccount)
+	var out types.GetOrderGroupResponse
+	if err := s.client.get(ctx, joinPath("portfolio", "order_groups", orderGroupID), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *OrderGroupsService) Delete(ctx context.Context, orderGroupID string, subaccount *int) error {
+	v := url.Values{}
+	encodeQueryInt(v, "subaccount", subaccount)
+	return s.client.delete(ctx, joinPath("portfolio", "order_groups", orderGroupID), v, nil, nil)
+}
+
+func (s *OrderGroupsService) Reset(ctx context.Context, orderGroupID string, subaccount *int) error {
+	v := url.Values{}
+	encodeQueryInt(v, "subaccount", subaccount)
+	return s.client.put(ctx, joinPath("portfolio", "order_groups", orderGroupID, "reset"), v, struct{}{}, nil)
+}
+
+func (s *OrderGroupsService) Trigger(ctx context.Context, orderGroupID string, subaccount *int) error {
+	v := url.Values{}
+	encodeQueryInt(v, "subaccount", subaccount)
+	return s.client.put(ctx, joinPath("portfolio", "order_groups", orderGroupID, "trigger"), v, struct{}{}, nil)
+}
+
+func (s *OrderGroupsService) UpdateLimit(ctx context.Context, orderGroupID string, req *types.UpdateOrderGroupLimitRequest) error {
+	return s.client.put(ctx, joinPath("portfolio", "order_groups", orderGroupID, "limit"), nil, req, nil)
+}
@@ -0,0 +1,339 @@
+package oddrip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+const (
+	// Prices are held in 1/10000 dollar units and counts in 1/100 contracts,
+	// matching the precision of the *_dollars and *_fp fields.
+	priceScale = 10000
+	countScale = 100
+)
+
+// ErrOrderbookGap is returned by OrderBook.Apply when an orderbook_delta does
+// not follow the previous message on its subscription, or arrives for a market
+// with no snapshot. The book for that subscription must be resynced.
+var ErrOrderbookGap = errors.New("orderbook sequence gap")
+
+// OrderBook keeps local yes/no price levels for a set of markets from the
+// orderbook_delta channel. Run subscribes and applies messages; reads are safe
+// for concurrent use.
+type OrderBook struct {
+	ws      *WSConn
+	tickers []string
+
+	mu      sync.RWMutex
+	books   map[string]*marketBook
+	seq     map[int]int
+	dropped map[int]bool
+	resyncs int
+}
+
+type marketBook struct {
+	sid int
+	yes map[int64]int64
+	no  map[int64]int64
+}
+
+func NewOrderBook(ws *WSConn, marketTickers ...string) *OrderBook {
+	return &OrderBook{
+		ws:      ws,
+		tickers: marketTickers,
+		books:   make(map[string]*marketBook),
+		seq:     make(map[int]int),
+		dropped: make(map[int]bool),
+	}
+}
+
+// Run subscribes to orderbook_delta for the book's markets and applies the
+// subscription's messages until ctx is done or the connection closes. On a
+// sequence gap or a message Apply rejects it unsubscribes and subscribes again
+// to get fresh snapshots.
+func (b *OrderBook) Run(ctx context.Context) error {
+	sub, err := b.subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				if err := b.ws.Err(); err != nil {
+					return err
+				}
+				return ErrWSClosed
+			}
+			if err := b.Apply(msg); err != nil {
+				if sub, err = b.resync(ctx, sub, msg.SID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func (b *OrderBook) subscribe(ctx context.Context) (*Subscription, error) {
+	return b.ws.Subscribe(ctx, types.SubscribeParams{
+		Channels:      []string{types.WSChannelOrderbookDelta},
+		MarketTickers: b.tickers,
+	})
+}
+
+func (b *OrderBook) resync(ctx context.Context, sub *Subscription, sid int) (*Subscription, error) {
+	b.mu.Lock()
+	b.dropSID(sid)
+	b.resyncs++
+	b.mu.Unlock()
+	// Keep reading the old subscription so a full queue cannot hold up the
+	// read loop before the unsubscribe response arrives.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case _, ok := <-sub.Messages():
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	if err := sub.Unsubscribe(ctx); err != nil {
+		var wsErr *WSError
+		if !errors.As(err, &wsErr) {
+			return nil, err
+		}
+		// The server no longer knows the SIDs; stop routing them here.
+		sids := sub.SIDs()
+		b.ws.subs.remove(sids...)
+		b.ws.unroute(sids...)
+	}
+	return b.subscribe(ctx)
+}
+
+// dropSID discards every book fed by sid and ignores its later messages.
+func (b *OrderBook) dropSID(sid int) {
+	b.dropped[sid] = true
+	delete(b.seq, sid)
+	for t, mb := range b.books {
+		if mb.sid == sid {
+			delete(b.books, t)
+		}
+	}
+}
+
+// Apply updates the book from an orderbook_snapshot or orderbook_delta message,
+// clears it on a reconnecting connection's "disconnected" event, treats an
+// "overflow" message as a gap, and ignores everything else. It returns an error
+// wrapping ErrOrderbookGap when the subscription needs a fresh snapshot, or a
+// parse error for a malformed message. Either way the subscription's books are
+// dropped until a snapshot arrives on a new subscription.
+func (b *OrderBook) Apply(msg *types.WSMessage) error {
+	if msg.Type == types.WSTypeDisconnected {
+		// Replayed subscriptions restart their sequences with new snapshots.
+		b.mu.Lock()
+		b.books = make(map[string]*marketBook)
+		b.seq = make(map[int]int)
+		b.dropped = make(map[int]bool)
+		b.mu.Unlock()
+		return nil
+	}
+	if msg.Type == types.WSTypeOverflow {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.dropSID(msg.SID)
+		return fmt.Errorf("%w: sid %d dropped frames", ErrOrderbookGap, msg.SID)
+	}
+	if msg.Type != types.WSTypeOrderbookSnapshot && msg.Type != types.WSTypeOrderbookDelta {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dropped[msg.SID] {
+		return nil
+	}
+	if err := b.apply(msg); err != nil {
+		b.dropSID(msg.SID)
+		return err
+	}
+	return nil
+}
+
+func (b *OrderBook) apply(msg *types.WSMessage) error {
+	last, seen := b.seq[msg.SID]
+	if seen && msg.Seq != last+1 {
+		return fmt.Errorf("%w: sid %d expected seq %d, got %d", ErrOrderbookGap, msg.SID, last+1, msg.Seq)
+	}
+
+	if msg.Type == types.WSTypeOrderbookSnapshot {
+		var snap types.OrderbookSnapshotMsg
+		if err := json.Unmarshal(msg.Msg, &snap); err != nil {
+			return err
+		}
+		mb := &marketBook{sid: msg.SID, yes: make(map[int64]int64), no: make(map[int64]int64)}
+		if err := loadLevels(mb.yes, snap.YesDollarsFp); err != nil {
+			return err
+		}
+		if err := loadLevels(mb.no, snap.NoDollarsFp); err != nil {
+			return err
+		}
+		b.books[snap.MarketTicker] = mb
+		b.seq[msg.SID] = msg.Seq
+		return nil
+	}
+
+	var d types.OrderbookDeltaMsg
+	if err := json.Unmarshal(msg.Msg, &d); err != nil {
+		return err
+	}
+	mb, ok := b.books[d.MarketTicker]
+	if !seen || !ok || mb.sid != msg.SID {
+		return fmt.Errorf("%w: sid %d delta for %s before snapshot", ErrOrderbookGap, msg.SID, d.MarketTicker)
+	}
+	if d.Side != types.OrderSideYes && d.Side != types.OrderSideNo {
+		return fmt.Errorf("orderbook_delta for %s: unknown side %q", d.MarketTicker, d.Side)
+	}
+	price, err := parseFixed(d.PriceDollars, 4)
+	if err != nil {
+		return err
+	}
+	delta, err := parseFixed(d.DeltaFp, 2)
+	if err != nil {
+		return err
+	}
+	levels := mb.yes
+	if d.Side == types.OrderSideNo {
+		levels = mb.no
+	}
+	if n := levels[price] + delta; n > 0 {
+		levels[price] = n
+	} else {
+		delete(levels, price)
+	}
+	b.seq[msg.SID] = msg.Seq
+	return nil
+}
+
+func loadLevels(dst map[int64]int64, levels [][]string) error {
+	for _, l := range levels {
+		if len(l) < 2 {
+			continue
+		}
+		price, err := parseFixed(l[0], 4)
+		if err != nil {
+			return err
+		}
+		count, err := parseFixed(l[1], 2)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			dst[price] = count
+		}
+	}
+	return nil
+}
+
+// BestBid returns the highest bid on side ("yes" or "no") for ticker.
+func (b *OrderBook) BestBid(ticker, side string) (types.OrderbookLevel, bool) {
+	levels := b.Depth(ticker, side, 1)
+	if len(levels) == 0 {
+		return types.OrderbookLevel{}, false
+	}
+	return levels[0], true
+}
+
+// BestBidAsk returns the best yes bid and the implied yes ask (one dollar minus
+// the best no bid, with that bid's size). ok is false unless both sides have
+// liquidity.
+func (b *OrderBook) BestBidAsk(ticker string) (bid, ask types.OrderbookLevel, ok bool) {
+	bid, okBid := b.BestBid(ticker, types.OrderSideYes)
+	noBid, okAsk := b.BestBid(ticker, types.OrderSideNo)
+	if !okBid || !okAsk {
+		return bid, ask, false
+	}
+	ask = types.OrderbookLevel{(priceScale - math.Round(noBid[0]*priceScale)) / priceScale, noBid[1]}
+	return bid, ask, true
+}
+
+// Depth returns up to n levels on side, best (highest price) first. n <= 0
+// returns the full ladder.
+func (b *OrderBook) Depth(ticker, side string, n int) []types.OrderbookLevel {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	mb, ok := b.books[ticker]
+	if !ok {
+		return nil
+	}
+	levels := mb.yes
+	if side == types.OrderSideNo {
+		levels = mb.no
+	}
+	prices := make([]int64, 0, len(levels))
+	for p := range levels {
+		prices = append(prices, p)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i] > prices[j] })
+	if n > 0 && n < len(prices) {
+		prices = prices[:n]
+	}
+	out := make([]types.OrderbookLevel, len(prices))
+	for i, p := range prices {
+		out[i] = types.OrderbookLevel{float64(p) / priceScale, float64(levels[p]) / countScale}
+	}
+	return out
+}
+
+// Ladder returns every level on side, best first.
+func (b *OrderBook) Ladder(ticker, side string) []types.OrderbookLevel {
+	return b.Depth(ticker, side, 0)
+}
+
+// Ready reports whether ticker has a current snapshot.
+func (b *OrderBook) Ready(ticker string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.books[ticker]
+	return ok
+}
+
+// Resyncs returns how many times Run has resubscribed after a gap.
+func (b *OrderBook) Resyncs() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.resyncs
+}
+
+// parseFixed parses a decimal string such as "0.9600" or "-54.00" into an
+// integer scaled by 10^places, truncating extra digits.
+func parseFixed(s string, places int) (int64, error) {
+	neg := strings.HasPrefix(s, "-")
+	whole, frac, _ := strings.Cut(strings.TrimLeft(s, "+-"), ".")
+	if len(frac) > places {
+		frac = frac[:places]
+	}
+	frac += strings.Repeat("0", places-len(frac))
+	v, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %q: %w", s, err)
+	}
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
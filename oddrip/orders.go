@@ -3,6 +3,7 @@ package oddrip
 import (
 	"context"
 	"errors"
+	"iter"
 	"net/url"
 
 	"github.com/UTXOnly/oddrip/oddrip/types"
@@ -113,3 +114,18 @@ func (s *OrdersService) BatchCancel(ctx context.Context, req *types.BatchCancelO
 	}
 	return &out, nil
 }
+
+func (s *OrdersService) All(ctx context.Context, opts *types.GetOrdersOpts) iter.Seq2[types.Order, error] {
+	var o types.GetOrdersOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Order, string, error) {
+		o.Cursor = cursor
+		resp, err := s.List(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Orders, resp.Cursor, nil
+	})
+}
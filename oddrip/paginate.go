@@ -0,0 +1,37 @@
+package oddrip
+
+import (
+	"context"
+	"iter"
+)
+
+// paginate walks a cursor-paginated endpoint starting at cursor. fetch returns
+// one page of items and the cursor for the next page; an empty next cursor ends
+// the sequence. Pages are fetched lazily as the caller ranges. A fetch error or
+// ctx cancellation is yielded once as the final element.
+func paginate[T any](ctx context.Context, cursor string, fetch func(cursor string) ([]T, string, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cur := cursor
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			items, next, err := fetch(cur)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" || next == cur {
+				return
+			}
+			cur = next
+		}
+	}
+}
@@ -3,6 +3,7 @@ package oddrip
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 
 	"github.com/UTXOnly/oddrip/oddrip/types"
@@ -24,6 +25,18 @@ func (s *PortfolioService) GetBalance(ctx context.Context, opts *types.GetBalanc
 	return &out, nil
 }
 
+func (s *PortfolioService) GetRestingOrderTotalValue(ctx context.Context, opts *types.GetRestingOrderTotalValueOpts) (*types.GetRestingOrderTotalValueResponse, error) {
+	v := url.Values{}
+	if opts != nil {
+		encodeQueryInt(v, "subaccount", opts.Subaccount)
+	}
+	var out types.GetRestingOrderTotalValueResponse
+	if err := s.client.get(ctx, joinPath("portfolio", "summary", "total_resting_order_value"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (s *PortfolioService) GetFills(ctx context.Context, opts *types.GetFillsOpts) (*types.GetFillsResponse, error) {
 	v := url.Values{}
 	if opts != nil {
@@ -83,6 +96,7 @@ func (s *PortfolioService) ListHistoricalFills(ctx context.Context, opts *types.
 	v := url.Values{}
 	if opts != nil {
 		encodeQuery(v, "ticker", opts.Ticker)
+		encodeQueryInt64(v, "min_ts", opts.MinTs)
 		encodeQueryInt64(v, "max_ts", opts.MaxTs)
 		encodeQueryInt64(v, "limit", opts.Limit)
 		encodeQuery(v, "cursor", opts.Cursor)
@@ -98,6 +112,7 @@ func (s *PortfolioService) ListHistoricalOrders(ctx context.Context, opts *types
 	v := url.Values{}
 	if opts != nil {
 		encodeQuery(v, "ticker", opts.Ticker)
+		encodeQueryInt64(v, "min_ts", opts.MinTs)
 		encodeQueryInt64(v, "max_ts", opts.MaxTs)
 		encodeQueryInt64(v, "limit", opts.Limit)
 		encodeQuery(v, "cursor", opts.Cursor)
@@ -108,3 +123,93 @@ func (s *PortfolioService) ListHistoricalOrders(ctx context.Context, opts *types
 	}
 	return &out, nil
 }
+
+func (s *PortfolioService) AllFills(ctx context.Context, opts *types.GetFillsOpts) iter.Seq2[types.Fill, error] {
+	var o types.GetFillsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Fill, string, error) {
+		o.Cursor = cursor
+		resp, err := s.GetFills(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Fills, resp.Cursor, nil
+	})
+}
+
+func (s *PortfolioService) AllMarketPositions(ctx context.Context, opts *types.GetPositionsOpts) iter.Seq2[types.MarketPosition, error] {
+	var o types.GetPositionsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.MarketPosition, string, error) {
+		o.Cursor = cursor
+		resp, err := s.GetPositions(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.MarketPositions, resp.Cursor, nil
+	})
+}
+
+func (s *PortfolioService) AllEventPositions(ctx context.Context, opts *types.GetPositionsOpts) iter.Seq2[types.EventPosition, error] {
+	var o types.GetPositionsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.EventPosition, string, error) {
+		o.Cursor = cursor
+		resp, err := s.GetPositions(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.EventPositions, resp.Cursor, nil
+	})
+}
+
+func (s *PortfolioService) AllSettlements(ctx context.Context, opts *types.GetSettlementsOpts) iter.Seq2[types.Settlement, error] {
+	var o types.GetSettlementsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Settlement, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListSettlements(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Settlements, resp.Cursor, nil
+	})
+}
+
+func (s *PortfolioService) AllHistoricalFills(ctx context.Context, opts *types.GetHistoricalArchiveOpts) iter.Seq2[types.Fill, error] {
+	var o types.GetHistoricalArchiveOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Fill, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListHistoricalFills(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Fills, resp.Cursor, nil
+	})
+}
+
+func (s *PortfolioService) AllHistoricalOrders(ctx context.Context, opts *types.GetHistoricalArchiveOpts) iter.Seq2[types.Order, error] {
+	var o types.GetHistoricalArchiveOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.Order, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListHistoricalOrders(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Orders, resp.Cursor, nil
+	})
+}
@@ -0,0 +1,27 @@
+package oddrip
+
+import (
+	"context"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+type SearchService struct {
+	client *Client
+}
+
+func (s *SearchService) GetTagsByCategories(ctx context.Context) (*types.GetTagsByCategoriesResponse, error) {
+	var out types.GetTagsByCategoriesResponse
+	if err := s.client.get(ctx, joinPath("search", "tags_by_categories"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *SearchService) GetFiltersBySport(ctx context.Context) (*types.GetFiltersBySportResponse, error) {
+	var out types.GetFiltersBySportResponse
+	if err := s.client.get(ctx, joinPath("search", "filters_by_sport"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,51 @@
+package oddrip
+
+import (
+	"context"
+	"iter"
+	"net/url"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+type StructuredTargetsService struct {
+	client *Client
+}
+
+func (s *StructuredTargetsService) List(ctx context.Context, opts *types.GetStructuredTargetsOpts) (*types.GetStructuredTargetsResponse, error) {
+	v := url.Values{}
+	if opts != nil {
+		encodeQuery(v, "type", opts.Type)
+		encodeQuery(v, "competition", opts.Competition)
+		encodeQueryInt(v, "page_size", opts.PageSize)
+		encodeQuery(v, "cursor", opts.Cursor)
+	}
+	var out types.GetStructuredTargetsResponse
+	if err := s.client.get(ctx, joinPath("structured_targets"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *StructuredTargetsService) Get(ctx context.Context, structuredTargetID string) (*types.GetStructuredTargetResponse, error) {
+	var out types.GetStructuredTargetResponse
+	if err := s.client.get(ctx, joinPath("structured_targets", structuredTargetID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *StructuredTargetsService) All(ctx context.Context, opts *types.GetStructuredTargetsOpts) iter.Seq2[types.StructuredTarget, error] {
+	var o types.GetStructuredTargetsOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.StructuredTarget, string, error) {
+		o.Cursor = cursor
+		resp, err := s.List(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.StructuredTargets, resp.Cursor, nil
+	})
+}
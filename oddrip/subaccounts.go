@@ -0,0 +1,77 @@
+package oddrip
+
+import (
+	"context"
+	"iter"
+	"net/url"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+type SubaccountsService struct {
+	client *Client
+}
+
+func (s *SubaccountsService) Create(ctx context.Context) (*types.CreateSubaccountResponse, error) {
+	var out types.CreateSubaccountResponse
+	if err := s.client.post(ctx, joinPath("portfolio", "subaccounts"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *SubaccountsService) Transfer(ctx context.Context, req *types.ApplySubaccountTransferRequest) (*types.ApplySubaccountTransferResponse, error) {
+	var out types.ApplySubaccountTransferResponse
+	if err := s.client.post(ctx, joinPath("portfolio", "subaccounts", "transfer"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *SubaccountsService) GetBalances(ctx context.Context) (*types.GetSubaccountBalancesResponse, error) {
+	var out types.GetSubaccountBalancesResponse
+	if err := s.client.get(ctx, joinPath("portfolio", "subaccounts", "balances"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *SubaccountsService) ListTransfers(ctx context.Context, opts *types.GetSubaccountTransfersOpts) (*types.GetSubaccountTransfersResponse, error) {
+	v := url.Values{}
+	if opts != nil {
+		encodeQueryInt64(v, "limit", opts.Limit)
+		encodeQuery(v, "cursor", opts.Cursor)
+	}
+	var out types.GetSubaccountTransfersResponse
+	if err := s.client.get(ctx, joinPath("portfolio", "subaccounts", "transfers"), v, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *SubaccountsService) GetNetting(ctx context.Context) (*types.GetSubaccountNettingResponse, error) {
+	var out types.GetSubaccountNettingResponse
+	if err := s.client.get(ctx, joinPath("portfolio", "subaccounts", "netting"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (s *SubaccountsService) UpdateNetting(ctx context.Context, req *types.UpdateSubaccountNettingRequest) error {
+	return s.client.put(ctx, joinPath("portfolio", "subaccounts", "netting"), nil, req, nil)
+}
+
+func (s *SubaccountsService) AllTransfers(ctx context.Context, opts *types.GetSubaccountTransfersOpts) iter.Seq2[types.SubaccountTransfer, error] {
+	var o types.GetSubaccountTransfersOpts
+	if opts != nil {
+		o = *opts
+	}
+	return paginate(ctx, o.Cursor, func(cursor string) ([]types.SubaccountTransfer, string, error) {
+		o.Cursor = cursor
+		resp, err := s.ListTransfers(ctx, &o)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Transfers, resp.Cursor, nil
+	})
+}
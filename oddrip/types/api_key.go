@@ -0,0 +1,36 @@
+package types
+
+const (
+	APIKeyScopeRead  = "read"
+	APIKeyScopeWrite = "write"
+)
+
+type APIKey struct {
+	APIKeyID string   `json:"api_key_id"`
+	Name     string   `json:"name"`
+	Scopes   []string `json:"scopes"`
+}
+
+type GetAPIKeysResponse struct {
+	APIKeys []APIKey `json:"api_keys"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name"`
+	PublicKey string   `json:"public_key"`
+	Scopes    []string `json:"scopes,omitempty"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKeyID string `json:"api_key_id"`
+}
+
+type GenerateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+type GenerateAPIKeyResponse struct {
+	APIKeyID   string `json:"api_key_id"`
+	PrivateKey string `json:"private_key"`
+}
@@ -0,0 +1,19 @@
+package types
+
+import "time"
+
+type BackfillOpts struct {
+	Ticker            string
+	MinTs             int64
+	MaxTs             int64
+	Window            time.Duration
+	Parallelism       int
+	RequestsPerSecond float64
+	Limit             *int64
+	Resume            *BackfillCheckpoint
+	OnCheckpoint      func(BackfillCheckpoint) error
+}
+
+type BackfillCheckpoint struct {
+	NextTs int64 `json:"next_ts"`
+}
@@ -29,6 +29,7 @@ const (
 )
 
 const (
+	PeriodInterval5Sec   = 0
 	PeriodInterval1Min   = 1
 	PeriodInterval1Hour  = 60
 	PeriodInterval1Day   = 1440
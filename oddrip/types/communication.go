@@ -0,0 +1,134 @@
+package types
+
+const (
+	RFQStatusOpen   = "open"
+	RFQStatusClosed = "closed"
+)
+
+const (
+	QuoteStatusOpen      = "open"
+	QuoteStatusAccepted  = "accepted"
+	QuoteStatusConfirmed = "confirmed"
+	QuoteStatusExecuted  = "executed"
+	QuoteStatusCancelled = "cancelled"
+)
+
+type GetCommunicationsIDResponse struct {
+	CommunicationsID string `json:"communications_id"`
+}
+
+type RFQ struct {
+	ID                  string           `json:"id"`
+	CreatorID           string           `json:"creator_id"`
+	MarketTicker        string           `json:"market_ticker"`
+	ContractsFp         string           `json:"contracts_fp"`
+	TargetCostDollars   *string          `json:"target_cost_dollars,omitempty"`
+	Status              string           `json:"status"`
+	CreatedTs           string           `json:"created_ts"`
+	MveCollectionTicker string           `json:"mve_collection_ticker,omitempty"`
+	MveSelectedLegs     []MveSelectedLeg `json:"mve_selected_legs,omitempty"`
+	RestRemainder       *bool            `json:"rest_remainder,omitempty"`
+	CancellationReason  string           `json:"cancellation_reason,omitempty"`
+	CreatorUserID       string           `json:"creator_user_id,omitempty"`
+	CancelledTs         *string          `json:"cancelled_ts,omitempty"`
+	UpdatedTs           *string          `json:"updated_ts,omitempty"`
+}
+
+type GetRFQsOpts struct {
+	Limit         *int
+	Cursor        string
+	EventTicker   string
+	MarketTicker  string
+	Subaccount    *int
+	Status        string
+	CreatorUserID string
+}
+
+type GetRFQsResponse struct {
+	RFQs   []RFQ  `json:"rfqs"`
+	Cursor string `json:"cursor"`
+}
+
+type GetRFQResponse struct {
+	RFQ RFQ `json:"rfq"`
+}
+
+type CreateRFQRequest struct {
+	MarketTicker      string  `json:"market_ticker"`
+	Contracts         *int    `json:"contracts,omitempty"`
+	ContractsFp       *string `json:"contracts_fp,omitempty"`
+	TargetCostDollars *string `json:"target_cost_dollars,omitempty"`
+	RestRemainder     bool    `json:"rest_remainder"`
+	ReplaceExisting   *bool   `json:"replace_existing,omitempty"`
+	SubtraderID       *string `json:"subtrader_id,omitempty"`
+	Subaccount        *int    `json:"subaccount,omitempty"`
+}
+
+type CreateRFQResponse struct {
+	ID string `json:"id"`
+}
+
+type Quote struct {
+	ID                   string  `json:"id"`
+	RFQID                string  `json:"rfq_id"`
+	CreatorID            string  `json:"creator_id"`
+	RFQCreatorID         string  `json:"rfq_creator_id"`
+	MarketTicker         string  `json:"market_ticker"`
+	ContractsFp          string  `json:"contracts_fp"`
+	YesBidDollars        string  `json:"yes_bid_dollars"`
+	NoBidDollars         string  `json:"no_bid_dollars"`
+	CreatedTs            string  `json:"created_ts"`
+	UpdatedTs            string  `json:"updated_ts"`
+	Status               string  `json:"status"`
+	AcceptedSide         *string `json:"accepted_side,omitempty"`
+	AcceptedTs           *string `json:"accepted_ts,omitempty"`
+	ConfirmedTs          *string `json:"confirmed_ts,omitempty"`
+	ExecutedTs           *string `json:"executed_ts,omitempty"`
+	CancelledTs          *string `json:"cancelled_ts,omitempty"`
+	RestRemainder        *bool   `json:"rest_remainder,omitempty"`
+	CancellationReason   string  `json:"cancellation_reason,omitempty"`
+	CreatorUserID        string  `json:"creator_user_id,omitempty"`
+	RFQCreatorUserID     string  `json:"rfq_creator_user_id,omitempty"`
+	RFQTargetCostDollars *string `json:"rfq_target_cost_dollars,omitempty"`
+	RFQCreatorOrderID    string  `json:"rfq_creator_order_id,omitempty"`
+	CreatorOrderID       string  `json:"creator_order_id,omitempty"`
+	YesContractsFp       *string `json:"yes_contracts_fp,omitempty"`
+	NoContractsFp        *string `json:"no_contracts_fp,omitempty"`
+}
+
+type GetQuotesOpts struct {
+	Limit                 *int
+	Cursor                string
+	EventTicker           string
+	MarketTicker          string
+	Status                string
+	QuoteCreatorUserID    string
+	RFQCreatorUserID      string
+	RFQCreatorSubtraderID string
+	RFQID                 string
+}
+
+type GetQuotesResponse struct {
+	Quotes []Quote `json:"quotes"`
+	Cursor string  `json:"cursor"`
+}
+
+type GetQuoteResponse struct {
+	Quote Quote `json:"quote"`
+}
+
+type CreateQuoteRequest struct {
+	RFQID         string `json:"rfq_id"`
+	YesBid        string `json:"yes_bid"`
+	NoBid         string `json:"no_bid"`
+	RestRemainder bool   `json:"rest_remainder"`
+	Subaccount    *int   `json:"subaccount,omitempty"`
+}
+
+type CreateQuoteResponse struct {
+	ID string `json:"id"`
+}
+
+type AcceptQuoteRequest struct {
+	AcceptedSide string `json:"accepted_side"`
+}
@@ -43,6 +43,7 @@ type Milestone struct {
 	Title                 string   `json:"title"`
 	NotificationMessage   string   `json:"notification_message"`
 	SourceID              *string  `json:"source_id,omitempty"`
+	SourceIDs             map[string]string `json:"source_ids,omitempty"`
 	Details               map[string]interface{} `json:"details"`
 	PrimaryEventTickers  []string `json:"primary_event_tickers"`
 	LastUpdatedTs        string   `json:"last_updated_ts"`
@@ -89,3 +90,40 @@ type GetEventMetadataResponse struct {
 	Competition      *string            `json:"competition,omitempty"`
 	CompetitionScope *string            `json:"competition_scope,omitempty"`
 }
+
+type GetEventCandlesticksOpts struct {
+	StartTs        int64
+	EndTs          int64
+	PeriodInterval int
+}
+
+type GetEventCandlesticksResponse struct {
+	MarketTickers      []string              `json:"market_tickers"`
+	MarketCandlesticks [][]MarketCandlestick `json:"market_candlesticks"`
+	AdjustedEndTs      int64                 `json:"adjusted_end_ts"`
+}
+
+type GetEventForecastPercentileHistoryOpts struct {
+	Percentiles    []int
+	StartTs        int64
+	EndTs          int64
+	PeriodInterval *int
+}
+
+type PercentilePoint struct {
+	Percentile           int     `json:"percentile"`
+	RawNumericalForecast float64 `json:"raw_numerical_forecast"`
+	NumericalForecast    float64 `json:"numerical_forecast"`
+	FormattedForecast    string  `json:"formatted_forecast"`
+}
+
+type ForecastPercentilesPoint struct {
+	EventTicker      string            `json:"event_ticker"`
+	EndPeriodTs      int64             `json:"end_period_ts"`
+	PeriodInterval   int               `json:"period_interval"`
+	PercentilePoints []PercentilePoint `json:"percentile_points"`
+}
+
+type GetEventForecastPercentileHistoryResponse struct {
+	ForecastHistory []ForecastPercentilesPoint `json:"forecast_history"`
+}
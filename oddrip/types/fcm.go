@@ -0,0 +1,28 @@
+package types
+
+const (
+	SettlementStatusAll       = "all"
+	SettlementStatusUnsettled = "unsettled"
+	SettlementStatusSettled   = "settled"
+)
+
+type GetFCMOrdersOpts struct {
+	SubtraderID string
+	Cursor      string
+	EventTicker string
+	Ticker      string
+	MinTs       *int64
+	MaxTs       *int64
+	Status      string
+	Limit       *int
+}
+
+type GetFCMPositionsOpts struct {
+	SubtraderID      string
+	Ticker           string
+	EventTicker      string
+	CountFilter      string
+	SettlementStatus string
+	Limit            *int
+	Cursor           string
+}
@@ -46,7 +46,24 @@ type GetHistoricalMarketCandlesticksOpts struct {
 
 type GetHistoricalArchiveOpts struct {
 	Ticker   string
+	MinTs    *int64
 	MaxTs    *int64
 	Limit    *int64
 	Cursor   string
 }
+
+type HistoryRangeOpts struct {
+	Ticker string
+	MinTs  *int64
+	MaxTs  *int64
+	Limit  *int64
+}
+
+type SettledMarketsRangeOpts struct {
+	EventTicker  string
+	Tickers      string
+	MveFilter    string
+	MinSettledTs *int64
+	MaxSettledTs *int64
+	Limit        *int64
+}
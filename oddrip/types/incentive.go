@@ -0,0 +1,40 @@
+package types
+
+const (
+	IncentiveStatusAll      = "all"
+	IncentiveStatusActive   = "active"
+	IncentiveStatusUpcoming = "upcoming"
+	IncentiveStatusClosed   = "closed"
+	IncentiveStatusPaidOut  = "paid_out"
+)
+
+const (
+	IncentiveTypeAll       = "all"
+	IncentiveTypeLiquidity = "liquidity"
+	IncentiveTypeVolume    = "volume"
+)
+
+type IncentiveProgram struct {
+	ID                string  `json:"id"`
+	MarketID          string  `json:"market_id"`
+	MarketTicker      string  `json:"market_ticker"`
+	IncentiveType     string  `json:"incentive_type"`
+	StartDate         string  `json:"start_date"`
+	EndDate           string  `json:"end_date"`
+	PeriodReward      int64   `json:"period_reward"`
+	PaidOut           bool    `json:"paid_out"`
+	DiscountFactorBps *int    `json:"discount_factor_bps,omitempty"`
+	TargetSizeFp      *string `json:"target_size_fp,omitempty"`
+}
+
+type GetIncentiveProgramsOpts struct {
+	Status string
+	Type   string
+	Limit  *int
+	Cursor string
+}
+
+type GetIncentiveProgramsResponse struct {
+	IncentivePrograms []IncentiveProgram `json:"incentive_programs"`
+	NextCursor        string             `json:"next_cursor,omitempty"`
+}
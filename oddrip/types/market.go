@@ -162,3 +162,59 @@ type GetTradesOpts struct {
 	MinTs  *int64
 	MaxTs  *int64
 }
+
+type BidAskDistribution struct {
+	OpenDollars  string `json:"open_dollars"`
+	LowDollars   string `json:"low_dollars"`
+	HighDollars  string `json:"high_dollars"`
+	CloseDollars string `json:"close_dollars"`
+}
+
+type PriceDistribution struct {
+	OpenDollars     *string `json:"open_dollars"`
+	LowDollars      *string `json:"low_dollars"`
+	HighDollars     *string `json:"high_dollars"`
+	CloseDollars    *string `json:"close_dollars"`
+	MeanDollars     *string `json:"mean_dollars"`
+	PreviousDollars *string `json:"previous_dollars"`
+	MinDollars      *string `json:"min_dollars,omitempty"`
+	MaxDollars      *string `json:"max_dollars,omitempty"`
+}
+
+type MarketCandlestick struct {
+	EndPeriodTs    int64              `json:"end_period_ts"`
+	YesBid         BidAskDistribution `json:"yes_bid"`
+	YesAsk         BidAskDistribution `json:"yes_ask"`
+	Price          PriceDistribution  `json:"price"`
+	VolumeFp       string             `json:"volume_fp"`
+	OpenInterestFp string             `json:"open_interest_fp"`
+}
+
+type GetMarketCandlesticksResponse struct {
+	Ticker       string              `json:"ticker"`
+	Candlesticks []MarketCandlestick `json:"candlesticks"`
+}
+
+type GetMarketCandlesticksOpts struct {
+	StartTs                  int64
+	EndTs                    int64
+	PeriodInterval           int
+	IncludeLatestBeforeStart *bool
+}
+
+type MarketCandlesticks struct {
+	MarketTicker string              `json:"market_ticker"`
+	Candlesticks []MarketCandlestick `json:"candlesticks"`
+}
+
+type BatchGetMarketCandlesticksResponse struct {
+	Markets []MarketCandlesticks `json:"markets"`
+}
+
+type BatchGetMarketCandlesticksOpts struct {
+	MarketTickers            []string
+	StartTs                  int64
+	EndTs                    int64
+	PeriodInterval           int
+	IncludeLatestBeforeStart *bool
+}
@@ -0,0 +1,36 @@
+package types
+
+type GetMilestonesOpts struct {
+	Limit              int
+	Cursor             string
+	MinimumStartDate   string
+	Category           string
+	Competition        string
+	SourceID           string
+	Type               string
+	RelatedEventTicker string
+	MinUpdatedTs       *int64
+}
+
+type GetMilestonesResponse struct {
+	Milestones []Milestone `json:"milestones"`
+	Cursor     string      `json:"cursor,omitempty"`
+}
+
+type GetMilestoneResponse struct {
+	Milestone Milestone `json:"milestone"`
+}
+
+type LiveData struct {
+	Type        string                 `json:"type"`
+	Details     map[string]interface{} `json:"details"`
+	MilestoneID string                 `json:"milestone_id"`
+}
+
+type GetLiveDataResponse struct {
+	LiveData LiveData `json:"live_data"`
+}
+
+type GetLiveDataBatchResponse struct {
+	LiveDatas []LiveData `json:"live_datas"`
+}
@@ -0,0 +1,86 @@
+package types
+
+const (
+	MultivariateCollectionStatusUnopened = "unopened"
+	MultivariateCollectionStatusOpen     = "open"
+	MultivariateCollectionStatusClosed   = "closed"
+)
+
+type AssociatedEvent struct {
+	Ticker        string   `json:"ticker"`
+	IsYesOnly     bool     `json:"is_yes_only"`
+	SizeMax       *int     `json:"size_max,omitempty"`
+	SizeMin       *int     `json:"size_min,omitempty"`
+	ActiveQuoters []string `json:"active_quoters"`
+}
+
+type MultivariateEventCollection struct {
+	CollectionTicker       string            `json:"collection_ticker"`
+	SeriesTicker           string            `json:"series_ticker"`
+	Title                  string            `json:"title"`
+	Description            string            `json:"description"`
+	OpenDate               string            `json:"open_date"`
+	CloseDate              string            `json:"close_date"`
+	AssociatedEvents       []AssociatedEvent `json:"associated_events"`
+	AssociatedEventTickers []string          `json:"associated_event_tickers"`
+	IsOrdered              bool              `json:"is_ordered"`
+	IsSingleMarketPerEvent bool              `json:"is_single_market_per_event"`
+	IsAllYes               bool              `json:"is_all_yes"`
+	SizeMin                int               `json:"size_min"`
+	SizeMax                int               `json:"size_max"`
+	FunctionalDescription  string            `json:"functional_description"`
+}
+
+type GetMultivariateEventCollectionsOpts struct {
+	Status                string
+	AssociatedEventTicker string
+	SeriesTicker          string
+	Limit                 *int
+	Cursor                string
+}
+
+type GetMultivariateEventCollectionsResponse struct {
+	MultivariateContracts []MultivariateEventCollection `json:"multivariate_contracts"`
+	Cursor                string                        `json:"cursor,omitempty"`
+}
+
+type GetMultivariateEventCollectionResponse struct {
+	MultivariateContract MultivariateEventCollection `json:"multivariate_contract"`
+}
+
+type TickerPair struct {
+	MarketTicker string `json:"market_ticker"`
+	EventTicker  string `json:"event_ticker"`
+	Side         string `json:"side"`
+}
+
+type CreateMarketInMultivariateEventCollectionRequest struct {
+	SelectedMarkets   []TickerPair `json:"selected_markets"`
+	WithMarketPayload *bool        `json:"with_market_payload,omitempty"`
+}
+
+type CreateMarketInMultivariateEventCollectionResponse struct {
+	EventTicker  string  `json:"event_ticker"`
+	MarketTicker string  `json:"market_ticker"`
+	Market       *Market `json:"market,omitempty"`
+}
+
+type LookupTickersForMarketInMultivariateEventCollectionRequest struct {
+	SelectedMarkets []TickerPair `json:"selected_markets"`
+}
+
+type LookupTickersForMarketInMultivariateEventCollectionResponse struct {
+	EventTicker  string `json:"event_ticker"`
+	MarketTicker string `json:"market_ticker"`
+}
+
+type LookupPoint struct {
+	EventTicker     string       `json:"event_ticker"`
+	MarketTicker    string       `json:"market_ticker"`
+	SelectedMarkets []TickerPair `json:"selected_markets"`
+	LastQueriedTs   string       `json:"last_queried_ts"`
+}
+
+type GetMultivariateEventCollectionLookupHistoryResponse struct {
+	LookupPoints []LookupPoint `json:"lookup_points"`
+}
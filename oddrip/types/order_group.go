@@ -0,0 +1,36 @@
+package types
+
+type OrderGroup struct {
+	ID                  string `json:"id"`
+	ContractsLimitFp    string `json:"contracts_limit_fp,omitempty"`
+	IsAutoCancelEnabled bool   `json:"is_auto_cancel_enabled"`
+}
+
+type GetOrderGroupsOpts struct {
+	Subaccount *int
+}
+
+type GetOrderGroupsResponse struct {
+	OrderGroups []OrderGroup `json:"order_groups"`
+}
+
+type GetOrderGroupResponse struct {
+	IsAutoCancelEnabled bool     `json:"is_auto_cancel_enabled"`
+	ContractsLimitFp    string   `json:"contracts_limit_fp,omitempty"`
+	Orders              []string `json:"orders"`
+}
+
+type CreateOrderGroupRequest struct {
+	Subaccount       *int    `json:"subaccount,omitempty"`
+	ContractsLimit   *int64  `json:"contracts_limit,omitempty"`
+	ContractsLimitFp *string `json:"contracts_limit_fp,omitempty"`
+}
+
+type CreateOrderGroupResponse struct {
+	OrderGroupID string `json:"order_group_id"`
+}
+
+type UpdateOrderGroupLimitRequest struct {
+	ContractsLimit   *int64  `json:"contracts_limit,omitempty"`
+	ContractsLimitFp *string `json:"contracts_limit_fp,omitempty"`
+}
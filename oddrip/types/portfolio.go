@@ -10,6 +10,14 @@ type GetBalanceOpts struct {
 	Subaccount *int
 }
 
+type GetRestingOrderTotalValueOpts struct {
+	Subaccount *int
+}
+
+type GetRestingOrderTotalValueResponse struct {
+	TotalRestingOrderValue int64 `json:"total_resting_order_value"`
+}
+
 type Fill struct {
 	FillID           string  `json:"fill_id"`
 	TradeID          string  `json:"trade_id"`
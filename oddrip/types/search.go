@@ -0,0 +1,19 @@
+package types
+
+type GetTagsByCategoriesResponse struct {
+	TagsByCategories map[string][]string `json:"tags_by_categories"`
+}
+
+type ScopeList struct {
+	Scopes []string `json:"scopes"`
+}
+
+type SportFilterDetails struct {
+	Scopes       []string             `json:"scopes"`
+	Competitions map[string]ScopeList `json:"competitions"`
+}
+
+type GetFiltersBySportResponse struct {
+	FiltersBySports map[string]SportFilterDetails `json:"filters_by_sports"`
+	SportOrdering   []string                      `json:"sport_ordering"`
+}
@@ -0,0 +1,44 @@
+package types
+
+const (
+	FeeTypeQuadratic              = "quadratic"
+	FeeTypeQuadraticWithMakerFees = "quadratic_with_maker_fees"
+	FeeTypeFlat                   = "flat"
+)
+
+type Series struct {
+	Ticker                 string                 `json:"ticker"`
+	Frequency              string                 `json:"frequency"`
+	Title                  string                 `json:"title"`
+	Category               string                 `json:"category"`
+	Tags                   []string               `json:"tags"`
+	SettlementSources      []SettlementSource     `json:"settlement_sources"`
+	ContractURL            string                 `json:"contract_url"`
+	ContractTermsURL       string                 `json:"contract_terms_url"`
+	ProductMetadata        map[string]interface{} `json:"product_metadata,omitempty"`
+	FeeType                string                 `json:"fee_type"`
+	FeeMultiplier          float64                `json:"fee_multiplier"`
+	AdditionalProhibitions []string               `json:"additional_prohibitions"`
+	VolumeFp               string                 `json:"volume_fp,omitempty"`
+	LastUpdatedTs          string                 `json:"last_updated_ts,omitempty"`
+}
+
+type GetSeriesListOpts struct {
+	Category               string
+	Tags                   string
+	IncludeProductMetadata *bool
+	IncludeVolume          *bool
+	MinUpdatedTs           *int64
+}
+
+type GetSeriesListResponse struct {
+	Series []Series `json:"series"`
+}
+
+type GetSeriesOpts struct {
+	IncludeVolume *bool
+}
+
+type GetSeriesResponse struct {
+	Series Series `json:"series"`
+}
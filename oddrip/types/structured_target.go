@@ -0,0 +1,27 @@
+package types
+
+type StructuredTarget struct {
+	ID            string                 `json:"id"`
+	Name          string                 `json:"name"`
+	Type          string                 `json:"type"`
+	Details       map[string]interface{} `json:"details,omitempty"`
+	SourceID      string                 `json:"source_id,omitempty"`
+	SourceIDs     map[string]string      `json:"source_ids,omitempty"`
+	LastUpdatedTs string                 `json:"last_updated_ts,omitempty"`
+}
+
+type GetStructuredTargetsOpts struct {
+	Type        string
+	Competition string
+	PageSize    *int
+	Cursor      string
+}
+
+type GetStructuredTargetsResponse struct {
+	StructuredTargets []StructuredTarget `json:"structured_targets"`
+	Cursor            string             `json:"cursor,omitempty"`
+}
+
+type GetStructuredTargetResponse struct {
+	StructuredTarget StructuredTarget `json:"structured_target"`
+}
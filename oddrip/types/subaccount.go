@@ -0,0 +1,56 @@
+package types
+
+type CreateSubaccountResponse struct {
+	SubaccountNumber int `json:"subaccount_number"`
+}
+
+type ApplySubaccountTransferRequest struct {
+	ClientTransferID string `json:"client_transfer_id"`
+	FromSubaccount   int    `json:"from_subaccount"`
+	ToSubaccount     int    `json:"to_subaccount"`
+	AmountCents      int64  `json:"amount_cents"`
+}
+
+type ApplySubaccountTransferResponse struct{}
+
+type SubaccountBalance struct {
+	SubaccountNumber int    `json:"subaccount_number"`
+	Balance          string `json:"balance"`
+	UpdatedTs        int64  `json:"updated_ts"`
+}
+
+type GetSubaccountBalancesResponse struct {
+	SubaccountBalances []SubaccountBalance `json:"subaccount_balances"`
+}
+
+type SubaccountTransfer struct {
+	TransferID     string `json:"transfer_id"`
+	FromSubaccount int    `json:"from_subaccount"`
+	ToSubaccount   int    `json:"to_subaccount"`
+	AmountCents    int64  `json:"amount_cents"`
+	CreatedTs      int64  `json:"created_ts"`
+}
+
+type GetSubaccountTransfersResponse struct {
+	Transfers []SubaccountTransfer `json:"transfers"`
+	Cursor    string               `json:"cursor,omitempty"`
+}
+
+type GetSubaccountTransfersOpts struct {
+	Limit  *int64
+	Cursor string
+}
+
+type SubaccountNettingConfig struct {
+	SubaccountNumber int  `json:"subaccount_number"`
+	Enabled          bool `json:"enabled"`
+}
+
+type GetSubaccountNettingResponse struct {
+	NettingConfigs []SubaccountNettingConfig `json:"netting_configs"`
+}
+
+type UpdateSubaccountNettingRequest struct {
+	SubaccountNumber int  `json:"subaccount_number"`
+	Enabled          bool `json:"enabled"`
+}
@@ -0,0 +1,355 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	WSTypeSubscribed         = "subscribed"
+	WSTypeUnsubscribed       = "unsubscribed"
+	WSTypeOK                 = "ok"
+	WSTypeError              = "error"
+	WSTypeOrderbookSnapshot  = "orderbook_snapshot"
+	WSTypeOrderbookDelta     = "orderbook_delta"
+	WSTypeTicker             = "ticker"
+	WSTypeTrade              = "trade"
+	WSTypeFill               = "fill"
+	WSTypeMarketPosition     = "market_position"
+	WSTypeMarketLifecycle    = "market_lifecycle_v2"
+	WSTypeEventLifecycle     = "event_lifecycle"
+	WSTypeMultivariateLookup = "multivariate_lookup"
+	WSTypeOrderGroupUpdates  = "order_group_updates"
+	WSTypeUserOrder          = "user_order"
+	WSTypeRFQCreated         = "rfq_created"
+	WSTypeRFQDeleted         = "rfq_deleted"
+	WSTypeQuoteCreated       = "quote_created"
+	WSTypeQuoteAccepted      = "quote_accepted"
+	WSTypeQuoteExecuted      = "quote_executed"
+
+	// Generated by the client, not the server.
+	WSTypeDisconnected = "disconnected"
+	WSTypeReconnected  = "reconnected"
+	WSTypeOverflow     = "overflow"
+)
+
+// ErrUnknownWSMessageType is returned by WSMessage.Decode for message types it
+// has no payload struct for.
+var ErrUnknownWSMessageType = errors.New("unknown websocket message type")
+
+type OrderbookSnapshotMsg struct {
+	MarketTicker string     `json:"market_ticker"`
+	MarketID     string     `json:"market_id"`
+	YesDollarsFp [][]string `json:"yes_dollars_fp,omitempty"`
+	NoDollarsFp  [][]string `json:"no_dollars_fp,omitempty"`
+}
+
+type OrderbookDeltaMsg struct {
+	MarketTicker  string  `json:"market_ticker"`
+	MarketID      string  `json:"market_id"`
+	PriceDollars  string  `json:"price_dollars"`
+	DeltaFp       string  `json:"delta_fp"`
+	Side          string  `json:"side"`
+	ClientOrderID string  `json:"client_order_id,omitempty"`
+	Subaccount    *int    `json:"subaccount,omitempty"`
+	Ts            *string `json:"ts,omitempty"`
+}
+
+type TickerMsg struct {
+	MarketTicker       string `json:"market_ticker"`
+	MarketID           string `json:"market_id"`
+	PriceDollars       string `json:"price_dollars"`
+	YesBidDollars      string `json:"yes_bid_dollars"`
+	YesAskDollars      string `json:"yes_ask_dollars"`
+	VolumeFp           string `json:"volume_fp"`
+	OpenInterestFp     string `json:"open_interest_fp"`
+	DollarVolume       int64  `json:"dollar_volume"`
+	DollarOpenInterest int64  `json:"dollar_open_interest"`
+	Ts                 int64  `json:"ts"`
+	Time               string `json:"time"`
+}
+
+type TradeMsg struct {
+	TradeID         string `json:"trade_id"`
+	MarketTicker    string `json:"market_ticker"`
+	YesPriceDollars string `json:"yes_price_dollars"`
+	NoPriceDollars  string `json:"no_price_dollars"`
+	CountFp         string `json:"count_fp"`
+	TakerSide       string `json:"taker_side"`
+	Ts              int64  `json:"ts"`
+}
+
+type FillMsg struct {
+	TradeID         string `json:"trade_id"`
+	OrderID         string `json:"order_id"`
+	MarketTicker    string `json:"market_ticker"`
+	IsTaker         bool   `json:"is_taker"`
+	Side            string `json:"side"`
+	YesPriceDollars string `json:"yes_price_dollars"`
+	CountFp         string `json:"count_fp"`
+	FeeCost         string `json:"fee_cost"`
+	Action          string `json:"action"`
+	Ts              int64  `json:"ts"`
+	ClientOrderID   string `json:"client_order_id,omitempty"`
+	PostPositionFp  string `json:"post_position_fp"`
+	PurchasedSide   string `json:"purchased_side"`
+	Subaccount      *int   `json:"subaccount,omitempty"`
+}
+
+type MarketPositionMsg struct {
+	UserID                 string `json:"user_id"`
+	MarketTicker           string `json:"market_ticker"`
+	PositionFp             string `json:"position_fp"`
+	PositionCost           int64  `json:"position_cost"`
+	PositionCostDollars    string `json:"position_cost_dollars"`
+	RealizedPnl            int64  `json:"realized_pnl"`
+	RealizedPnlDollars     string `json:"realized_pnl_dollars"`
+	FeesPaid               int64  `json:"fees_paid"`
+	FeesPaidDollars        string `json:"fees_paid_dollars"`
+	PositionFeeCost        int64  `json:"position_fee_cost"`
+	PositionFeeCostDollars string `json:"position_fee_cost_dollars"`
+	VolumeFp               string `json:"volume_fp"`
+	Subaccount             *int   `json:"subaccount,omitempty"`
+}
+
+type MarketLifecycleMetadata struct {
+	Name                 string          `json:"name,omitempty"`
+	Title                string          `json:"title,omitempty"`
+	YesSubTitle          string          `json:"yes_sub_title,omitempty"`
+	NoSubTitle           string          `json:"no_sub_title,omitempty"`
+	RulesPrimary         string          `json:"rules_primary,omitempty"`
+	RulesSecondary       string          `json:"rules_secondary,omitempty"`
+	CanCloseEarly        *bool           `json:"can_close_early,omitempty"`
+	EventTicker          string          `json:"event_ticker,omitempty"`
+	ExpectedExpirationTs *int64          `json:"expected_expiration_ts,omitempty"`
+	StrikeType           string          `json:"strike_type,omitempty"`
+	FloorStrike          *float64        `json:"floor_strike,omitempty"`
+	CapStrike            *float64        `json:"cap_strike,omitempty"`
+	CustomStrike         json.RawMessage `json:"custom_strike,omitempty"`
+}
+
+type MarketLifecycleMsg struct {
+	EventType          string                   `json:"event_type"`
+	MarketTicker       string                   `json:"market_ticker"`
+	OpenTs             *int64                   `json:"open_ts,omitempty"`
+	CloseTs            *int64                   `json:"close_ts,omitempty"`
+	Result             string                   `json:"result,omitempty"`
+	DeterminationTs    *int64                   `json:"determination_ts,omitempty"`
+	SettlementValue    string                   `json:"settlement_value,omitempty"`
+	SettledTs          *int64                   `json:"settled_ts,omitempty"`
+	IsDeactivated      *bool                    `json:"is_deactivated,omitempty"`
+	AdditionalMetadata *MarketLifecycleMetadata `json:"additional_metadata,omitempty"`
+}
+
+type EventLifecycleMsg struct {
+	EventTicker          string `json:"event_ticker"`
+	Title                string `json:"title"`
+	Subtitle             string `json:"subtitle"`
+	CollateralReturnType string `json:"collateral_return_type"`
+	SeriesTicker         string `json:"series_ticker"`
+	StrikeDate           *int64 `json:"strike_date,omitempty"`
+	StrikePeriod         string `json:"strike_period,omitempty"`
+}
+
+type MultivariateSelectedMarket struct {
+	EventTicker  string `json:"event_ticker"`
+	MarketTicker string `json:"market_ticker"`
+	Side         string `json:"side"`
+}
+
+type MultivariateLookupMsg struct {
+	CollectionTicker string                       `json:"collection_ticker"`
+	EventTicker      string                       `json:"event_ticker"`
+	MarketTicker     string                       `json:"market_ticker"`
+	SelectedMarkets  []MultivariateSelectedMarket `json:"selected_markets"`
+}
+
+type OrderGroupUpdateMsg struct {
+	EventType        string `json:"event_type"`
+	OrderGroupID     string `json:"order_group_id"`
+	ContractsLimitFp string `json:"contracts_limit_fp,omitempty"`
+}
+
+type UserOrderMsg struct {
+	OrderID                 string  `json:"order_id"`
+	UserID                  string  `json:"user_id"`
+	Ticker                  string  `json:"ticker"`
+	Status                  string  `json:"status"`
+	Side                    string  `json:"side"`
+	IsYes                   bool    `json:"is_yes"`
+	YesPriceDollars         string  `json:"yes_price_dollars"`
+	FillCountFp             string  `json:"fill_count_fp"`
+	RemainingCountFp        string  `json:"remaining_count_fp"`
+	InitialCountFp          string  `json:"initial_count_fp"`
+	TakerFillCostDollars    string  `json:"taker_fill_cost_dollars"`
+	MakerFillCostDollars    string  `json:"maker_fill_cost_dollars"`
+	TakerFeesDollars        string  `json:"taker_fees_dollars"`
+	MakerFeesDollars        string  `json:"maker_fees_dollars"`
+	ClientOrderID           string  `json:"client_order_id"`
+	OrderGroupID            string  `json:"order_group_id,omitempty"`
+	SelfTradePreventionType string  `json:"self_trade_prevention_type,omitempty"`
+	CreatedTime             string  `json:"created_time"`
+	LastUpdateTime          *string `json:"last_update_time,omitempty"`
+	ExpirationTime          *string `json:"expiration_time,omitempty"`
+	SubaccountNumber        *int    `json:"subaccount_number,omitempty"`
+}
+
+type RFQLeg struct {
+	EventTicker               string `json:"event_ticker,omitempty"`
+	MarketTicker              string `json:"market_ticker,omitempty"`
+	Side                      string `json:"side,omitempty"`
+	YesSettlementValueDollars string `json:"yes_settlement_value_dollars,omitempty"`
+}
+
+type RFQCreatedMsg struct {
+	ID                  string   `json:"id"`
+	CreatorID           string   `json:"creator_id"`
+	MarketTicker        string   `json:"market_ticker"`
+	EventTicker         string   `json:"event_ticker,omitempty"`
+	ContractsFp         string   `json:"contracts_fp,omitempty"`
+	TargetCostDollars   string   `json:"target_cost_dollars,omitempty"`
+	CreatedTs           string   `json:"created_ts"`
+	MveCollectionTicker string   `json:"mve_collection_ticker,omitempty"`
+	MveSelectedLegs     []RFQLeg `json:"mve_selected_legs,omitempty"`
+}
+
+type RFQDeletedMsg struct {
+	ID                string `json:"id"`
+	CreatorID         string `json:"creator_id"`
+	MarketTicker      string `json:"market_ticker"`
+	EventTicker       string `json:"event_ticker,omitempty"`
+	ContractsFp       string `json:"contracts_fp,omitempty"`
+	TargetCostDollars string `json:"target_cost_dollars,omitempty"`
+	DeletedTs         string `json:"deleted_ts"`
+}
+
+type QuoteCreatedMsg struct {
+	QuoteID               string `json:"quote_id"`
+	RFQID                 string `json:"rfq_id"`
+	QuoteCreatorID        string `json:"quote_creator_id"`
+	MarketTicker          string `json:"market_ticker"`
+	EventTicker           string `json:"event_ticker,omitempty"`
+	YesBidDollars         string `json:"yes_bid_dollars"`
+	NoBidDollars          string `json:"no_bid_dollars"`
+	YesContractsOfferedFp string `json:"yes_contracts_offered_fp,omitempty"`
+	NoContractsOfferedFp  string `json:"no_contracts_offered_fp,omitempty"`
+	RFQTargetCostDollars  string `json:"rfq_target_cost_dollars,omitempty"`
+	CreatedTs             string `json:"created_ts"`
+}
+
+type QuoteAcceptedMsg struct {
+	QuoteID               string `json:"quote_id"`
+	RFQID                 string `json:"rfq_id"`
+	QuoteCreatorID        string `json:"quote_creator_id"`
+	MarketTicker          string `json:"market_ticker"`
+	EventTicker           string `json:"event_ticker,omitempty"`
+	YesBidDollars         string `json:"yes_bid_dollars"`
+	NoBidDollars          string `json:"no_bid_dollars"`
+	AcceptedSide          string `json:"accepted_side,omitempty"`
+	ContractsAcceptedFp   string `json:"contracts_accepted_fp,omitempty"`
+	YesContractsOfferedFp string `json:"yes_contracts_offered_fp,omitempty"`
+	NoContractsOfferedFp  string `json:"no_contracts_offered_fp,omitempty"`
+	RFQTargetCostDollars  string `json:"rfq_target_cost_dollars,omitempty"`
+}
+
+type QuoteExecutedMsg struct {
+	QuoteID        string `json:"quote_id"`
+	RFQID          string `json:"rfq_id"`
+	QuoteCreatorID string `json:"quote_creator_id"`
+	RFQCreatorID   string `json:"rfq_creator_id"`
+	OrderID        string `json:"order_id"`
+	ClientOrderID  string `json:"client_order_id"`
+	MarketTicker   string `json:"market_ticker"`
+	ExecutedTs     string `json:"executed_ts"`
+}
+
+// DisconnectedMsg is emitted when a reconnecting connection drops. SIDs keep
+// their meaning, but sequence numbers restart and snapshots are resent once
+// subscriptions are replayed.
+type DisconnectedMsg struct {
+	Error string `json:"error"`
+}
+
+// ReconnectedMsg is emitted once a reconnecting connection has redialed,
+// before its subscriptions are replayed.
+type ReconnectedMsg struct {
+	Attempts int `json:"attempts"`
+}
+
+// UnsubscribedMsg is the decoded form of an "unsubscribed" response, which
+// carries no payload; SID is copied from the message.
+type UnsubscribedMsg struct {
+	SID int `json:"sid"`
+}
+
+// OverflowMsg is emitted on a SID after Dropped of its frames were discarded
+// because the consumer fell behind. Sequenced state for that SID (e.g. an order
+// book) should be resynced.
+type OverflowMsg struct {
+	Dropped int64 `json:"dropped"`
+}
+
+// Decode unmarshals Msg into the payload struct for m.Type and returns a
+// pointer to it, e.g. *TickerMsg for "ticker" or *OrderbookDeltaMsg for
+// "orderbook_delta". Unrecognised types return ErrUnknownWSMessageType.
+func (m WSMessage) Decode() (any, error) {
+	var v any
+	switch m.Type {
+	case WSTypeSubscribed:
+		v = new(SubscribedMsg)
+	case WSTypeOK:
+		v = new(OKMsg)
+	case WSTypeError:
+		v = new(ErrorMsg)
+	case WSTypeUnsubscribed:
+		return &UnsubscribedMsg{SID: m.SID}, nil
+	case WSTypeOrderbookSnapshot:
+		v = new(OrderbookSnapshotMsg)
+	case WSTypeOrderbookDelta:
+		v = new(OrderbookDeltaMsg)
+	case WSTypeTicker:
+		v = new(TickerMsg)
+	case WSTypeTrade:
+		v = new(TradeMsg)
+	case WSTypeFill:
+		v = new(FillMsg)
+	case WSTypeMarketPosition:
+		v = new(MarketPositionMsg)
+	case WSTypeMarketLifecycle:
+		v = new(MarketLifecycleMsg)
+	case WSTypeEventLifecycle:
+		v = new(EventLifecycleMsg)
+	case WSTypeMultivariateLookup:
+		v = new(MultivariateLookupMsg)
+	case WSTypeOrderGroupUpdates:
+		v = new(OrderGroupUpdateMsg)
+	case WSTypeUserOrder:
+		v = new(UserOrderMsg)
+	case WSTypeRFQCreated:
+		v = new(RFQCreatedMsg)
+	case WSTypeRFQDeleted:
+		v = new(RFQDeletedMsg)
+	case WSTypeQuoteCreated:
+		v = new(QuoteCreatedMsg)
+	case WSTypeQuoteAccepted:
+		v = new(QuoteAcceptedMsg)
+	case WSTypeQuoteExecuted:
+		v = new(QuoteExecutedMsg)
+	case WSTypeDisconnected:
+		v = new(DisconnectedMsg)
+	case WSTypeReconnected:
+		v = new(ReconnectedMsg)
+	case WSTypeOverflow:
+		v = new(OverflowMsg)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownWSMessageType, m.Type)
+	}
+	if len(m.Msg) == 0 {
+		return v, nil
+	}
+	if err := json.Unmarshal(m.Msg, v); err != nil {
+		return nil, fmt.Errorf("decode %s message: %w", m.Type, err)
+	}
+	return v, nil
+}
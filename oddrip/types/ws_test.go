@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 )
 
@@ -56,3 +57,33 @@ func TestUpdateSubscriptionParams_Action(t *testing.T) {
 }
 
 func intPtr(i int) *int { return &i }
+
+func TestWSMessage_Decode(t *testing.T) {
+	var m WSMessage
+	raw := `{"type":"orderbook_delta","sid":2,"seq":7,"msg":{"market_ticker":"FED-23DEC-T3.00","market_id":"m1","price_dollars":"0.9600","delta_fp":"-54.00","side":"yes"}}`
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, ok := v.(*OrderbookDeltaMsg)
+	if !ok || d.MarketTicker != "FED-23DEC-T3.00" || d.DeltaFp != "-54.00" || d.Side != "yes" {
+		t.Fatalf("decoded %#v", v)
+	}
+
+	v, err = WSMessage{Type: WSTypeOrderbookSnapshot, Msg: json.RawMessage(`{"market_ticker":"T","yes_dollars_fp":[["0.0800","300.00"]]}`)}.Decode()
+	if s, ok := v.(*OrderbookSnapshotMsg); err != nil || !ok || s.YesDollarsFp[0][1] != "300.00" {
+		t.Fatalf("snapshot %#v %v", v, err)
+	}
+
+	v, err = WSMessage{Type: WSTypeUnsubscribed, SID: 4}.Decode()
+	if u, ok := v.(*UnsubscribedMsg); err != nil || !ok || u.SID != 4 {
+		t.Fatalf("unsubscribed %#v %v", v, err)
+	}
+
+	if _, err := (WSMessage{Type: "mystery"}).Decode(); !errors.Is(err, ErrUnknownWSMessageType) {
+		t.Fatalf("unknown type err: %v", err)
+	}
+}
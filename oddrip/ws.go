@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"sync"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"github.com/UTXOnly/oddrip/oddrip/internal/retry"
 	"github.com/UTXOnly/oddrip/oddrip/types"
 )
 
@@ -22,8 +24,11 @@ const defaultWSPath = "/trade-api/ws/v2"
 var (
 	ErrWSClosed     = errors.New("websocket closed")
 	ErrWSAuthRequired = errors.New("websocket requires auth")
+	ErrWSStale        = errors.New("websocket connection stale")
 )
 
+const wsControlWait = 5 * time.Second
+
 type WSConn struct {
 	conn     *websocket.Conn
 	auth     AuthProvider
@@ -35,8 +40,25 @@ type WSConn struct {
 	readErr  error
 	pendMu   sync.Mutex
 	pending  map[int]chan *wsEnvelope
-	msgChan  chan *types.WSMessage
+	out      *wsQueue
+	dropped  atomic.Int64
 	readDone chan struct{}
+
+	routeMu sync.Mutex
+	routes  map[int]*Subscription
+	joining map[int]*Subscription
+
+	dial        func(ctx context.Context) (*websocket.Conn, error)
+	reconnect   *retry.Config
+	heartbeat   time.Duration
+	idleTimeout time.Duration
+	overflow    WSOverflowPolicy
+	recorder    *WSRecorder
+	writes      chan wsWrite
+	writerDone  chan struct{}
+	ctx         context.Context
+	cancel      context.CancelFunc
+	subs        wsSubState
 }
 
 type wsEnvelope struct {
@@ -53,6 +75,11 @@ type wsOpts struct {
 	scheme string
 	host  string
 	path  string
+	reconnect *RetryConfig
+	heartbeat   time.Duration
+	idleTimeout time.Duration
+	overflow    WSOverflowPolicy
+	recorder    *WSRecorder
 }
 
 func WSScheme(scheme string) WSOption {
@@ -73,6 +100,27 @@ func WSPath(path string) WSOption {
 	}
 }
 
+// WSReconnect makes the connection redial with backoff when it drops,
+// re-signing the handshake and replaying active subscriptions. SIDs returned
+// by Subscribe stay valid across reconnects. MaxAttempts <= 0 retries until
+// Close.
+func WSReconnect(cfg RetryConfig) WSOption {
+	return func(o *wsOpts) {
+		o.reconnect = &cfg
+	}
+}
+
+// WSKeepalive sends a ping every heartbeat and treats the connection as dead
+// when nothing (data, ping or pong) arrives for idleTimeout. A dead connection
+// closes Messages with Err() wrapping ErrWSStale, or reconnects when
+// WSReconnect is set. Either duration may be zero to disable it.
+func WSKeepalive(heartbeat, idleTimeout time.Duration) WSOption {
+	return func(o *wsOpts) {
+		o.heartbeat = heartbeat
+		o.idleTimeout = idleTimeout
+	}
+}
+
 func (c *Client) ConnectWS(ctx context.Context, opts ...WSOption) (*WSConn, error) {
 	if c.auth == nil {
 		return nil, ErrWSAuthRequired
@@ -85,19 +133,26 @@ func (c *Client) ConnectWS(ctx context.Context, opts ...WSOption) (*WSConn, erro
 		cfg.scheme = "wss"
 	}
 	u := url.URL{Scheme: cfg.scheme, Host: cfg.host, Path: cfg.path}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-	if err := c.auth.Apply(req); err != nil {
-		return nil, err
-	}
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
+	dial := func(ctx context.Context) (*websocket.Conn, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.auth.Apply(req); err != nil {
+			return nil, err
+		}
+		dialer := websocket.Dialer{
+			HandshakeTimeout: 10 * time.Second,
+		}
+		conn, _, err := dialer.DialContext(ctx, u.String(), req.Header)
+		if err != nil {
+			return nil, fmt.Errorf("ws dial: %w", err)
+		}
+		return conn, nil
 	}
-	conn, _, err := dialer.DialContext(ctx, u.String(), req.Header)
+	conn, err := dial(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("ws dial: %w", err)
+		return nil, err
 	}
 	ws := &WSConn{
 		conn:     conn,
@@ -105,48 +160,168 @@ func (c *Client) ConnectWS(ctx context.Context, opts ...WSOption) (*WSConn, erro
 		host:     cfg.host,
 		path:     cfg.path,
 		pending:  make(map[int]chan *wsEnvelope),
-		msgChan:  make(chan *types.WSMessage, 256),
 		readDone: make(chan struct{}),
+		routes:   make(map[int]*Subscription),
+		joining:  make(map[int]*Subscription),
+		dial:     dial,
+
+		heartbeat:   cfg.heartbeat,
+		idleTimeout: cfg.idleTimeout,
+		overflow:    cfg.overflow,
+		recorder:    cfg.recorder,
+	}
+	ws.out = newWSQueue(wsOverflowEvict, new(atomic.Int64))
+	ws.ctx, ws.cancel = context.WithCancel(context.Background())
+	if cfg.reconnect != nil {
+		ws.reconnect = &retry.Config{
+			MaxAttempts:   cfg.reconnect.MaxAttempts,
+			InitialDelay:  cfg.reconnect.InitialDelay,
+			MaxDelay:      cfg.reconnect.MaxDelay,
+			JitterPercent: cfg.reconnect.JitterPercent,
+		}
+		ws.subs.init()
 	}
 	ws.nextID.Store(1)
+	ws.writes = make(chan wsWrite)
+	ws.writerDone = make(chan struct{})
+	go ws.writeLoop()
 	go ws.readLoop()
 	return ws, nil
 }
 
 func (ws *WSConn) readLoop() {
 	defer close(ws.readDone)
-	defer close(ws.msgChan)
+	defer ws.out.close()
+	defer ws.closeRoutes()
+	stopReplay := func() {}
+	defer func() { stopReplay() }()
+	var replayDone chan struct{}
 	for {
-		_, data, err := ws.conn.ReadMessage()
-		if err != nil {
-			ws.mu.Lock()
-			ws.readErr = err
-			ws.mu.Unlock()
-			ws.drainPending(err)
+		err := ws.readFrames()
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			err = fmt.Errorf("%w: nothing received for %s", ErrWSStale, ws.idleTimeout)
+		}
+		ws.mu.Lock()
+		ws.readErr = err
+		closed := ws.closed
+		ws.mu.Unlock()
+		ws.drainPending(err)
+		stopReplay()
+		if ws.reconnect == nil || closed {
+			return
+		}
+		ws.emitEvent(types.WSTypeDisconnected, types.DisconnectedMsg{Error: err.Error()})
+		if !ws.redial() {
 			return
 		}
+		// Replayed subscribes are answered through this loop, so they run
+		// on their own goroutine, after the previous socket's replay exits.
+		ctx, cancel := context.WithCancel(ws.ctx)
+		prev, done := replayDone, make(chan struct{})
+		stopReplay, replayDone = cancel, done
+		go func() {
+			defer close(done)
+			if prev != nil {
+				<-prev
+			}
+			ws.replay(ctx)
+		}()
+	}
+}
+
+func (ws *WSConn) readFrames() error {
+	ws.mu.Lock()
+	conn := ws.conn
+	ws.mu.Unlock()
+	stop := ws.keepalive(conn)
+	defer stop()
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		ws.touch(conn)
 		var env wsEnvelope
 		if err := json.Unmarshal(data, &env); err != nil {
+			ws.recorder.record(WSRecordRecv, data)
 			continue
 		}
+		if ws.subs.translateInbound(&env) {
+			// Record the SIDs the consumer sees, which outlive the socket.
+			data, _ = json.Marshal(env)
+		}
+		ws.recorder.record(WSRecordRecv, data)
+		if env.Type == types.WSTypeSubscribed {
+			ws.register(&env)
+		}
 		ws.pendMu.Lock()
 		ch, ok := ws.pending[env.ID]
-		delete(ws.pending, env.ID)
 		ws.pendMu.Unlock()
 		if ok && ch != nil {
 			select {
 			case ch <- &env:
+				// The command's caller has the response.
+				continue
 			default:
 			}
 		}
-		msg := &types.WSMessage{Type: env.Type, SID: env.SID, Seq: env.Seq, Msg: env.Msg}
-		select {
-		case ws.msgChan <- msg:
-		default:
+		if !ws.deliver(&types.WSMessage{Type: env.Type, SID: env.SID, Seq: env.Seq, Msg: env.Msg}) {
+			conn.Close()
+			return ErrWSOverflow
 		}
 	}
 }
 
+// keepalive installs the read deadline and ping/pong handlers on conn and
+// starts the heartbeat. The returned func stops the heartbeat.
+func (ws *WSConn) keepalive(conn *websocket.Conn) func() {
+	if ws.idleTimeout > 0 {
+		ws.touch(conn)
+		conn.SetPongHandler(func(string) error {
+			ws.touch(conn)
+			return nil
+		})
+		conn.SetPingHandler(func(data string) error {
+			ws.touch(conn)
+			err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(wsControlWait))
+			if err == websocket.ErrCloseSent {
+				return nil
+			}
+			return err
+		})
+	}
+	if ws.heartbeat <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(ws.heartbeat)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsControlWait)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (ws *WSConn) touch(conn *websocket.Conn) {
+	if ws.idleTimeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(ws.idleTimeout))
+	}
+}
+
+func (ws *WSConn) emit(msg *types.WSMessage) bool {
+	return ws.out.push(msg, ws.ctx.Done())
+}
+
 func (ws *WSConn) drainPending(err error) {
 	ws.pendMu.Lock()
 	for _, ch := range ws.pending {
@@ -181,7 +356,7 @@ func (ws *WSConn) sendAndWait(ctx context.Context, id int, payload interface{},
 		ws.pendMu.Unlock()
 	}()
 
-	if err := ws.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+	if err := ws.write(ctx, websocket.TextMessage, data); err != nil {
 		return nil, err
 	}
 	var out []*wsEnvelope
@@ -214,7 +389,9 @@ func (ws *WSConn) sendAndWait(ctx context.Context, id int, payload interface{},
 	}
 }
 
-func (ws *WSConn) Subscribe(ctx context.Context, params types.SubscribeParams) ([]types.SubscribedResponse, error) {
+// Subscribe subscribes to params.Channels and returns a handle that receives
+// the frames for the resulting SIDs.
+func (ws *WSConn) Subscribe(ctx context.Context, params types.SubscribeParams) (*Subscription, error) {
 	if len(params.Channels) == 0 {
 		return nil, errors.New("channels required")
 	}
@@ -224,26 +401,24 @@ func (ws *WSConn) Subscribe(ctx context.Context, params types.SubscribeParams) (
 		Cmd:    "subscribe",
 		Params: params,
 	}
-	envs, err := ws.sendAndWait(ctx, id, cmd, len(params.Channels))
-	if err != nil {
-		return nil, err
-	}
-	result := make([]types.SubscribedResponse, 0, len(envs))
-	for _, env := range envs {
-		if env.Type != "subscribed" {
-			continue
-		}
-		var m types.SubscribedMsg
-		if len(env.Msg) > 0 {
-			json.Unmarshal(env.Msg, &m)
+	sub := &Subscription{ws: ws, q: newWSQueue(ws.overflow, &ws.dropped)}
+	ws.routeMu.Lock()
+	ws.joining[id] = sub
+	ws.routeMu.Unlock()
+	defer func() {
+		ws.routeMu.Lock()
+		delete(ws.joining, id)
+		ws.routeMu.Unlock()
+	}()
+	ws.subs.track(id, params, nil)
+	defer ws.subs.untrack(id)
+	if _, err := ws.sendAndWait(ctx, id, cmd, len(params.Channels)); err != nil {
+		if sids := sub.SIDs(); len(sids) > 0 {
+			ws.Unsubscribe(ctx, sids)
 		}
-		result = append(result, types.SubscribedResponse{
-			ID:   env.ID,
-			Type: "subscribed",
-			Msg:  m,
-		})
+		return nil, err
 	}
-	return result, nil
+	return sub, nil
 }
 
 func (ws *WSConn) Unsubscribe(ctx context.Context, sids []int) error {
@@ -252,9 +427,13 @@ func (ws *WSConn) Unsubscribe(ctx context.Context, sids []int) error {
 	}
 	id := ws.nextIDVal()
 	cmd := types.UnsubscribeCommand{ID: id, Cmd: "unsubscribe"}
-	cmd.Params.Sids = sids
-	_, err := ws.sendAndWait(ctx, id, cmd, len(sids))
-	return err
+	cmd.Params.Sids = ws.subs.serverSIDs(sids)
+	if _, err := ws.sendAndWait(ctx, id, cmd, len(sids)); err != nil {
+		return err
+	}
+	ws.subs.remove(sids...)
+	ws.unroute(sids...)
+	return nil
 }
 
 func (ws *WSConn) ListSubscriptions(ctx context.Context) (*types.ListSubscriptionsResponse, error) {
@@ -274,6 +453,7 @@ func (ws *WSConn) ListSubscriptions(ctx context.Context) (*types.ListSubscriptio
 	if len(env.Msg) > 0 {
 		json.Unmarshal(env.Msg, &list.Msg)
 	}
+	ws.subs.publicList(list.Msg)
 	return &list, nil
 }
 
@@ -283,10 +463,16 @@ func (ws *WSConn) UpdateSubscription(ctx context.Context, params types.UpdateSub
 	}
 	id := ws.nextIDVal()
 	cmd := types.UpdateSubscriptionCommand{ID: id, Cmd: "update_subscription", Params: params}
+	if params.SID != nil {
+		sid := ws.subs.serverSIDs([]int{*params.SID})[0]
+		cmd.Params.SID = &sid
+	}
+	cmd.Params.Sids = ws.subs.serverSIDs(params.Sids)
 	envs, err := ws.sendAndWait(ctx, id, cmd, 1)
 	if err != nil {
 		return nil, err
 	}
+	ws.subs.update(params)
 	if len(envs) == 0 {
 		return nil, errors.New("no response")
 	}
@@ -303,8 +489,29 @@ func (ws *WSConn) UpdateSubscription(ctx context.Context, params types.UpdateSub
 	return &ok, nil
 }
 
+// Messages receives client events and frames that neither a Subscription nor
+// a waiting command claims, such as errors without a command ID. It is not
+// subject to WSOverflow: a consumer that never reads it loses the oldest
+// frames and nothing else.
 func (ws *WSConn) Messages() <-chan *types.WSMessage {
-	return ws.msgChan
+	return ws.out.ch
+}
+
+// Dropped returns how many frames have been discarded across every
+// Subscription because a consumer fell behind.
+func (ws *WSConn) Dropped() int64 {
+	return ws.dropped.Load()
+}
+
+// Err returns the error that ended the connection once Messages is closed,
+// e.g. one wrapping ErrWSStale, or nil after Close.
+func (ws *WSConn) Err() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.closed {
+		return nil
+	}
+	return ws.readErr
 }
 
 func (ws *WSConn) Close() error {
@@ -314,11 +521,20 @@ func (ws *WSConn) Close() error {
 		return nil
 	}
 	ws.closed = true
-	err := ws.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-	if e := ws.conn.Close(); e != nil && err == nil {
+	ws.mu.Unlock()
+
+	// Frames queued before Close are written first, then the close frame;
+	// the writer exits after it.
+	ctx, cancel := context.WithTimeout(context.Background(), wsWriteWait)
+	err := ws.write(ctx, websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	cancel()
+	ws.cancel()
+	ws.mu.Lock()
+	conn := ws.conn
+	ws.mu.Unlock()
+	if e := conn.Close(); e != nil && err == nil {
 		err = e
 	}
-	ws.mu.Unlock()
 	select {
 	case <-ws.readDone:
 		return err
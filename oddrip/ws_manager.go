@@ -0,0 +1,222 @@
+package oddrip
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+const defaultManagerBatch = 500
+
+// SubscriptionManager keeps one subscription per channel in line with a
+// declared set of market tickers. Set records the desired tickers; Reconcile
+// sends the add_markets/delete_markets (or subscribe/unsubscribe) commands
+// needed to get there, in batches, and checks the result against
+// list_subscriptions.
+type SubscriptionManager struct {
+	ws    *WSConn
+	batch int
+
+	mu      sync.Mutex
+	desired map[string][]string
+
+	// reconcile serializes Reconcile and guards subs and actual.
+	reconcile sync.Mutex
+	subs      map[string]*Subscription
+	actual    map[string]map[string]bool
+}
+
+// NewSubscriptionManager returns a manager sending at most batchSize tickers
+// per command (500 if batchSize <= 0).
+func NewSubscriptionManager(ws *WSConn, batchSize int) *SubscriptionManager {
+	if batchSize <= 0 {
+		batchSize = defaultManagerBatch
+	}
+	return &SubscriptionManager{
+		ws:      ws,
+		batch:   batchSize,
+		desired: make(map[string][]string),
+		subs:    make(map[string]*Subscription),
+		actual:  make(map[string]map[string]bool),
+	}
+}
+
+// Set declares the tickers wanted on channel, replacing the previous set. An
+// empty set unsubscribes the channel on the next Reconcile.
+func (m *SubscriptionManager) Set(channel string, tickers []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.desired[channel] = slices.Clone(tickers)
+}
+
+// Subscription returns the current subscription for channel, or nil. When
+// Reconcile has to resubscribe a channel the old handle's Messages is closed
+// and a new handle takes its place.
+func (m *SubscriptionManager) Subscription(channel string) *Subscription {
+	m.reconcile.Lock()
+	defer m.reconcile.Unlock()
+	return m.subs[channel]
+}
+
+// Reconcile brings every channel in line with its declared tickers. Channels
+// whose SIDs are missing from list_subscriptions are subscribed again. On
+// error the commands that succeeded are kept and the next call resumes from
+// there.
+func (m *SubscriptionManager) Reconcile(ctx context.Context) error {
+	m.reconcile.Lock()
+	defer m.reconcile.Unlock()
+	m.mu.Lock()
+	desired := make(map[string][]string, len(m.desired))
+	for ch, tickers := range m.desired {
+		desired[ch] = tickers
+	}
+	m.mu.Unlock()
+
+	channels := make([]string, 0, len(desired))
+	for ch := range desired {
+		channels = append(channels, ch)
+	}
+	sort.Strings(channels)
+	for _, ch := range channels {
+		if err := m.apply(ctx, ch, desired[ch]); err != nil {
+			return fmt.Errorf("%s: %w", ch, err)
+		}
+	}
+
+	missing, err := m.missing(ctx)
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+	for _, ch := range missing {
+		// Close the stale handle so its reader moves to the new one.
+		if sids := m.subs[ch].SIDs(); len(sids) > 0 {
+			m.ws.subs.remove(sids...)
+			m.ws.unroute(sids...)
+		}
+		m.forget(ch)
+		if err := m.apply(ctx, ch, desired[ch]); err != nil {
+			return fmt.Errorf("%s: %w", ch, err)
+		}
+	}
+	if missing, err = m.missing(ctx); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("subscriptions missing from list_subscriptions: %v", missing)
+	}
+	return nil
+}
+
+// apply sends the commands that turn the channel's actual tickers into want.
+// Additions go first so a subscription is never left empty.
+func (m *SubscriptionManager) apply(ctx context.Context, channel string, want []string) error {
+	wantSet := make(map[string]bool, len(want))
+	for _, t := range want {
+		wantSet[t] = true
+	}
+	sub := m.subs[channel]
+	if sub != nil && len(sub.SIDs()) == 0 {
+		// Closed by the connection, e.g. a rejected replay.
+		m.forget(channel)
+		sub = nil
+	}
+	if len(wantSet) == 0 {
+		if sub == nil {
+			return nil
+		}
+		if err := sub.Unsubscribe(ctx); err != nil {
+			return err
+		}
+		m.forget(channel)
+		return nil
+	}
+
+	have := m.actual[channel]
+	var add, del []string
+	for t := range wantSet {
+		if !have[t] {
+			add = append(add, t)
+		}
+	}
+	for t := range have {
+		if !wantSet[t] {
+			del = append(del, t)
+		}
+	}
+	sort.Strings(add)
+	sort.Strings(del)
+
+	if sub == nil {
+		first := add[:min(m.batch, len(add))]
+		s, err := m.ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{channel}, MarketTickers: first})
+		if err != nil {
+			return err
+		}
+		sub = s
+		m.subs[channel] = sub
+		have = make(map[string]bool, len(wantSet))
+		m.actual[channel] = have
+		for _, t := range first {
+			have[t] = true
+		}
+		add = add[len(first):]
+	}
+	if err := m.update(ctx, sub, "add_markets", add, have); err != nil {
+		return err
+	}
+	return m.update(ctx, sub, "delete_markets", del, have)
+}
+
+func (m *SubscriptionManager) update(ctx context.Context, sub *Subscription, action string, tickers []string, have map[string]bool) error {
+	sids := sub.SIDs()
+	if len(sids) == 0 {
+		return ErrWSClosed
+	}
+	for batch := range slices.Chunk(tickers, m.batch) {
+		sid := sids[0]
+		if _, err := m.ws.UpdateSubscription(ctx, types.UpdateSubscriptionParams{SID: &sid, MarketTickers: batch, Action: action}); err != nil {
+			return err
+		}
+		for _, t := range batch {
+			if action == "add_markets" {
+				have[t] = true
+			} else {
+				delete(have, t)
+			}
+		}
+	}
+	return nil
+}
+
+// missing returns the managed channels whose SID the server no longer lists.
+func (m *SubscriptionManager) missing(ctx context.Context) ([]string, error) {
+	if len(m.subs) == 0 {
+		return nil, nil
+	}
+	list, err := m.ws.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	listed := make(map[int]string, len(list.Msg))
+	for _, item := range list.Msg {
+		listed[item.SID] = item.Channel
+	}
+	var out []string
+	for ch, sub := range m.subs {
+		sids := sub.SIDs()
+		if len(sids) == 0 || listed[sids[0]] != ch {
+			out = append(out, ch)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (m *SubscriptionManager) forget(channel string) {
+	delete(m.subs, channel)
+	delete(m.actual, channel)
+}
@@ -0,0 +1,233 @@
+package oddrip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+// maxShardFactor is the largest shard_factor the server accepts.
+const maxShardFactor = 100
+
+// poolDedupWindow is roughly how many recent frames a PoolSubscription
+// remembers to spot one delivered by more than one shard.
+const poolDedupWindow = 4096
+
+// WSPool is a set of WebSocket connections that split high-volume channels
+// (ticker, trade) between them with shard_factor/shard_key.
+type WSPool struct {
+	conns  []*WSConn
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	nextSID int
+}
+
+// ConnectWSPool opens shards connections with the same options. Each
+// connection receives 1/shards of every subscription made through the pool.
+func (c *Client) ConnectWSPool(ctx context.Context, shards int, opts ...WSOption) (*WSPool, error) {
+	if shards < 1 || shards > maxShardFactor {
+		return nil, fmt.Errorf("shards must be between 1 and %d", maxShardFactor)
+	}
+	p := &WSPool{}
+	for i := 0; i < shards; i++ {
+		ws, err := c.ConnectWS(ctx, opts...)
+		if err != nil {
+			for _, conn := range p.conns {
+				conn.Close()
+			}
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		p.conns = append(p.conns, ws)
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	return p, nil
+}
+
+// Conns returns the pool's connections, indexed by shard key.
+func (p *WSPool) Conns() []*WSConn {
+	return slices.Clone(p.conns)
+}
+
+// Subscribe subscribes every connection to params with its shard key and
+// merges the results into one PoolSubscription. params must not set
+// ShardFactor or ShardKey. The API documents sharding for the communications
+// channel only; if the server ignores it on another channel every connection
+// receives the full stream, and the copies after the first are dropped and
+// counted by PoolSubscription.Duplicates.
+func (p *WSPool) Subscribe(ctx context.Context, params types.SubscribeParams) (*PoolSubscription, error) {
+	if params.ShardFactor != nil || params.ShardKey != nil {
+		return nil, errors.New("shard_factor and shard_key are set by the pool")
+	}
+	ps := &PoolSubscription{
+		q:      newWSQueue(WSOverflowBlock, new(atomic.Int64)),
+		shards: make([]*Subscription, len(p.conns)),
+		dedup:  shardDedup{seed: maphash.MakeSeed()},
+	}
+	factor := len(p.conns)
+	for i, ws := range p.conns {
+		key := i
+		shard := params
+		shard.ShardFactor, shard.ShardKey = &factor, &key
+		sub, err := ws.Subscribe(ctx, shard)
+		if err != nil {
+			ps.Unsubscribe(ctx)
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		ps.shards[i] = sub
+	}
+
+	// Server SIDs are per connection, so each shard's SIDs get a pool-wide
+	// one. One goroutine forwards each shard, keeping every SID in order.
+	p.mu.Lock()
+	pool := make([]map[int]int, len(ps.shards))
+	for i, sub := range ps.shards {
+		pool[i] = make(map[int]int)
+		for _, sid := range sub.SIDs() {
+			p.nextSID++
+			pool[i][sid] = p.nextSID
+			ps.sids = append(ps.sids, p.nextSID)
+		}
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i, sub := range ps.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range sub.Messages() {
+				if ps.dedup.duplicate(i, msg) {
+					continue
+				}
+				m := *msg
+				if sid, ok := pool[i][m.SID]; ok {
+					m.SID = sid
+				}
+				ps.q.push(&m, p.ctx.Done())
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		ps.q.close()
+	}()
+	return ps, nil
+}
+
+// Close closes every connection.
+func (p *WSPool) Close() error {
+	p.cancel()
+	var errs []error
+	for i, ws := range p.conns {
+		if err := ws.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("shard %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PoolSubscription is a subscription spread over a WSPool. Messages merges
+// the shards with SIDs rewritten to pool-wide values; frames for one SID keep
+// their order. Client events (disconnected, reconnected) arrive once per
+// affected connection. Messages is closed once every shard has ended.
+type PoolSubscription struct {
+	q      *wsQueue
+	shards []*Subscription
+	sids   []int
+	dedup  shardDedup
+}
+
+func (ps *PoolSubscription) Messages() <-chan *types.WSMessage {
+	return ps.q.ch
+}
+
+// SIDs returns the pool-wide subscription IDs.
+func (ps *PoolSubscription) SIDs() []int {
+	return slices.Clone(ps.sids)
+}
+
+// Dropped returns how many frames the shards discarded under their overflow
+// policy. A slow reader of Messages backs up into the shard buffers.
+func (ps *PoolSubscription) Dropped() int64 {
+	var n int64
+	for _, sub := range ps.shards {
+		if sub != nil {
+			n += sub.Dropped()
+		}
+	}
+	return n
+}
+
+// Duplicates returns how many frames were dropped because another shard had
+// already delivered the same one. A growing count means the server is not
+// sharding the channel, and the pool only adds load.
+func (ps *PoolSubscription) Duplicates() int64 {
+	return ps.dedup.dups.Load()
+}
+
+// Unsubscribe unsubscribes every shard.
+func (ps *PoolSubscription) Unsubscribe(ctx context.Context) error {
+	var errs []error
+	for i, sub := range ps.shards {
+		if sub == nil {
+			continue
+		}
+		if err := sub.Unsubscribe(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shard %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// shardDedup recognises data frames with the same type and payload arriving
+// from different shards. Repeats from the same shard are kept.
+type shardDedup struct {
+	mu   sync.Mutex
+	seed maphash.Seed
+	cur  map[uint64]int
+	prev map[uint64]int
+	dups atomic.Int64
+}
+
+func (d *shardDedup) duplicate(shard int, msg *types.WSMessage) bool {
+	if msg.SID == 0 || len(msg.Msg) == 0 {
+		return false
+	}
+	switch msg.Type {
+	case types.WSTypeOverflow, types.WSTypeError, types.WSTypeSubscribed, types.WSTypeUnsubscribed, types.WSTypeOK:
+		return false
+	}
+	var h maphash.Hash
+	h.SetSeed(d.seed)
+	h.WriteString(msg.Type)
+	h.WriteByte(0)
+	h.Write(msg.Msg)
+	key := h.Sum64()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	from, ok := d.cur[key]
+	if !ok {
+		from, ok = d.prev[key]
+	}
+	if ok {
+		if from == shard {
+			return false
+		}
+		d.dups.Add(1)
+		return true
+	}
+	if d.cur == nil || len(d.cur) >= poolDedupWindow {
+		d.prev, d.cur = d.cur, make(map[uint64]int)
+	}
+	d.cur[key] = shard
+	return false
+}
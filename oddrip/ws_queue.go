@@ -0,0 +1,157 @@
+package oddrip
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+// ErrWSOverflow ends the connection when a consumer falls behind under
+// WSOverflowDisconnect.
+var ErrWSOverflow = errors.New("websocket consumer overflow")
+
+// WSOverflowPolicy decides what happens when a message channel is full.
+type WSOverflowPolicy int
+
+const (
+	// WSOverflowDropNewest discards the incoming frame (the default).
+	WSOverflowDropNewest WSOverflowPolicy = iota
+	// WSOverflowDropOldest discards the oldest buffered frames to make room.
+	WSOverflowDropOldest
+	// WSOverflowBlock waits for the consumer, stalling every subscription on
+	// the connection.
+	WSOverflowBlock
+	// WSOverflowDisconnect closes the socket with ErrWSOverflow (and
+	// reconnects under WSReconnect).
+	WSOverflowDisconnect
+
+	// wsOverflowEvict silently discards the oldest frames. It is used for the
+	// shared channel, which must never stall or end the connection.
+	wsOverflowEvict WSOverflowPolicy = -1
+)
+
+// WSOverflow sets the policy applied to every Subscription when its buffer is
+// full. Dropped frames are counted (WSConn.Dropped, Subscription.Dropped) and
+// followed by a types.WSTypeOverflow message on the affected SID once there is
+// room. WSConn.Messages is exempt: when it is not read it keeps the newest
+// frames, without counting or marking the ones it discards.
+func WSOverflow(policy WSOverflowPolicy) WSOption {
+	return func(o *wsOpts) {
+		o.overflow = policy
+	}
+}
+
+// wsQueue is a message channel with an overflow policy. Overflow markers owed
+// to each SID are delivered ahead of the next frame that fits.
+type wsQueue struct {
+	mu       sync.Mutex
+	ch       chan *types.WSMessage
+	policy   WSOverflowPolicy
+	closed   bool
+	quit     chan struct{}
+	quitOnce sync.Once
+	dropped  atomic.Int64
+	total    *atomic.Int64
+	overflow map[int]int64
+}
+
+func newWSQueue(policy WSOverflowPolicy, total *atomic.Int64) *wsQueue {
+	return &wsQueue{
+		ch:       make(chan *types.WSMessage, wsMessageBuffer),
+		policy:   policy,
+		quit:     make(chan struct{}),
+		total:    total,
+		overflow: make(map[int]int64),
+	}
+}
+
+// push delivers msg according to the policy. It returns false when the
+// connection should be dropped. done aborts a blocked push.
+func (q *wsQueue) push(msg *types.WSMessage, done <-chan struct{}) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return true
+	}
+	if q.policy == wsOverflowEvict {
+		for {
+			select {
+			case q.ch <- msg:
+				return true
+			default:
+			}
+			select {
+			case <-q.ch:
+			default:
+			}
+		}
+	}
+	for sid, n := range q.overflow {
+		data, _ := json.Marshal(types.OverflowMsg{Dropped: n})
+		select {
+		case q.ch <- &types.WSMessage{Type: types.WSTypeOverflow, SID: sid, Msg: data}:
+			delete(q.overflow, sid)
+		default:
+		}
+	}
+	select {
+	case q.ch <- msg:
+		return true
+	default:
+	}
+	if q.policy == WSOverflowDropOldest {
+		// Make room for msg only; owed markers wait for the consumer.
+		for {
+			select {
+			case old := <-q.ch:
+				q.drop(old)
+			default:
+			}
+			select {
+			case q.ch <- msg:
+				return true
+			default:
+			}
+		}
+	}
+	switch q.policy {
+	case WSOverflowBlock:
+		select {
+		case q.ch <- msg:
+		case <-q.quit:
+		case <-done:
+		}
+	case WSOverflowDisconnect:
+		q.drop(msg)
+		return false
+	default:
+		q.drop(msg)
+	}
+	return true
+}
+
+func (q *wsQueue) drop(msg *types.WSMessage) {
+	if msg.Type == types.WSTypeOverflow {
+		// Re-owe a dropped marker's count rather than the marker itself.
+		var o types.OverflowMsg
+		json.Unmarshal(msg.Msg, &o)
+		q.overflow[msg.SID] += o.Dropped
+		return
+	}
+	q.dropped.Add(1)
+	q.total.Add(1)
+	q.overflow[msg.SID]++
+}
+
+func (q *wsQueue) close() {
+	q.quitOnce.Do(func() { close(q.quit) })
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+}
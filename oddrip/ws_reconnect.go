@@ -0,0 +1,288 @@
+package oddrip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+// wsSubState tracks subscriptions on a reconnecting connection. Callers see
+// stable public SIDs; toPublic/toServer map them to the SIDs the current
+// socket assigned. On a plain connection it is left uninitialised and every
+// method is a no-op or identity mapping.
+type wsSubState struct {
+	mu       sync.Mutex
+	enabled  bool
+	nextSID  int
+	active   map[int]*types.SubscribeParams
+	toPublic map[int]int
+	toServer map[int]int
+	intents  map[int]*subscribeIntent
+}
+
+// subscribeIntent is an in-flight subscribe command. sids is set when the
+// command replays existing subscriptions, keyed by channel.
+type subscribeIntent struct {
+	params types.SubscribeParams
+	sids   map[string]int
+}
+
+func (s *wsSubState) init() {
+	s.enabled = true
+	s.active = make(map[int]*types.SubscribeParams)
+	s.toPublic = make(map[int]int)
+	s.toServer = make(map[int]int)
+	s.intents = make(map[int]*subscribeIntent)
+}
+
+func (s *wsSubState) track(id int, params types.SubscribeParams, sids map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.enabled {
+		s.intents[id] = &subscribeIntent{params: params, sids: sids}
+	}
+}
+
+func (s *wsSubState) untrack(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.enabled {
+		delete(s.intents, id)
+	}
+}
+
+// translateInbound rewrites server SIDs in env to public SIDs, registering new
+// subscriptions as their "subscribed" responses arrive. It reports whether env
+// changed.
+func (s *wsSubState) translateInbound(env *wsEnvelope) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled {
+		return false
+	}
+	if env.Type == types.WSTypeSubscribed {
+		var m types.SubscribedMsg
+		if json.Unmarshal(env.Msg, &m) != nil {
+			return false
+		}
+		in := s.intents[env.ID]
+		pub, ok := 0, false
+		if in != nil {
+			pub, ok = in.sids[m.Channel]
+		}
+		if !ok {
+			s.nextSID++
+			pub = s.nextSID
+		}
+		s.toPublic[m.SID] = pub
+		s.toServer[pub] = m.SID
+		if in != nil {
+			p := in.params
+			p.Channels = []string{m.Channel}
+			s.active[pub] = &p
+		}
+		m.SID = pub
+		env.Msg, _ = json.Marshal(m)
+		return true
+	}
+	if pub, ok := s.toPublic[env.SID]; ok && env.SID != 0 && pub != env.SID {
+		env.SID = pub
+		return true
+	}
+	return false
+}
+
+func (s *wsSubState) serverSIDs(sids []int) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled || sids == nil {
+		return sids
+	}
+	out := make([]int, len(sids))
+	for i, sid := range sids {
+		out[i] = sid
+		if srv, ok := s.toServer[sid]; ok {
+			out[i] = srv
+		}
+	}
+	return out
+}
+
+func (s *wsSubState) publicList(items []types.ListSubscriptionsItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled {
+		return
+	}
+	for i := range items {
+		if pub, ok := s.toPublic[items[i].SID]; ok {
+			items[i].SID = pub
+		}
+	}
+}
+
+func (s *wsSubState) remove(sids ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled {
+		return
+	}
+	for _, sid := range sids {
+		delete(s.active, sid)
+		if srv, ok := s.toServer[sid]; ok {
+			delete(s.toPublic, srv)
+			delete(s.toServer, sid)
+		}
+	}
+}
+
+// update applies an add_markets/delete_markets change to the stored params so
+// replays subscribe to the current market set. params carries public SIDs.
+func (s *wsSubState) update(params types.UpdateSubscriptionParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled {
+		return
+	}
+	sids := params.Sids
+	if params.SID != nil {
+		sids = append([]int{*params.SID}, sids...)
+	}
+	tickers := params.MarketTickers
+	if params.MarketTicker != "" {
+		tickers = append([]string{params.MarketTicker}, tickers...)
+	}
+	ids := params.MarketIDs
+	if params.MarketID != "" {
+		ids = append([]string{params.MarketID}, ids...)
+	}
+	for _, sid := range sids {
+		p, ok := s.active[sid]
+		if !ok {
+			continue
+		}
+		if p.MarketTicker != "" {
+			p.MarketTickers = append(p.MarketTickers, p.MarketTicker)
+			p.MarketTicker = ""
+		}
+		if p.MarketID != "" {
+			p.MarketIDs = append(p.MarketIDs, p.MarketID)
+			p.MarketID = ""
+		}
+		if params.Action == "add_markets" {
+			p.MarketTickers = appendMissing(p.MarketTickers, tickers)
+			p.MarketIDs = appendMissing(p.MarketIDs, ids)
+		} else {
+			p.MarketTickers = slices.DeleteFunc(p.MarketTickers, func(t string) bool { return slices.Contains(tickers, t) })
+			p.MarketIDs = slices.DeleteFunc(p.MarketIDs, func(id string) bool { return slices.Contains(ids, id) })
+		}
+	}
+}
+
+func appendMissing(dst, src []string) []string {
+	for _, v := range src {
+		if !slices.Contains(dst, v) {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
+
+// resetServer forgets the previous socket's SIDs and returns the active
+// subscriptions to replay, ordered by public SID.
+func (s *wsSubState) resetServer() map[int]types.SubscribeParams {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toPublic = make(map[int]int)
+	s.toServer = make(map[int]int)
+	out := make(map[int]types.SubscribeParams, len(s.active))
+	for sid, p := range s.active {
+		out[sid] = *p
+	}
+	return out
+}
+
+func (ws *WSConn) emitEvent(typ string, payload interface{}) {
+	data, _ := json.Marshal(payload)
+	msg := &types.WSMessage{Type: typ, Msg: data}
+	ws.recorder.recordClient(msg)
+	ws.broadcast(msg)
+}
+
+// redial dials until it succeeds, the attempt limit is reached or the
+// connection is closed. It reports whether a new socket is in place.
+func (ws *WSConn) redial() bool {
+	for attempt := 0; ws.reconnect.MaxAttempts <= 0 || attempt < ws.reconnect.MaxAttempts; attempt++ {
+		t := time.NewTimer(ws.reconnect.Delay(attempt, 0))
+		select {
+		case <-ws.ctx.Done():
+			t.Stop()
+			return false
+		case <-t.C:
+		}
+		conn, err := ws.dial(ws.ctx)
+		if err != nil {
+			ws.mu.Lock()
+			ws.readErr = err
+			ws.mu.Unlock()
+			continue
+		}
+		ws.mu.Lock()
+		if ws.closed {
+			ws.mu.Unlock()
+			conn.Close()
+			return false
+		}
+		ws.conn = conn
+		ws.readErr = nil
+		ws.mu.Unlock()
+		ws.emitEvent(types.WSTypeReconnected, types.ReconnectedMsg{Attempts: attempt + 1})
+		return true
+	}
+	return false
+}
+
+// replay resubscribes every active subscription on the new socket under its
+// existing public SID. A subscription the server rejects is dropped after an
+// error message carrying its SID is delivered to it; a connection failure
+// leaves the rest for the next reconnect.
+func (ws *WSConn) replay(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	subs := ws.subs.resetServer()
+	sids := make([]int, 0, len(subs))
+	for sid := range subs {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+	for _, sid := range sids {
+		if ctx.Err() != nil {
+			return
+		}
+		params := subs[sid]
+		id := ws.nextIDVal()
+		ws.subs.track(id, params, map[string]int{params.Channels[0]: sid})
+		_, err := ws.sendAndWait(ctx, id, types.SubscribeCommand{ID: id, Cmd: "subscribe", Params: params}, 1)
+		ws.subs.untrack(id)
+		if err == nil {
+			continue
+		}
+		var wsErr *WSError
+		if !errors.As(err, &wsErr) {
+			return
+		}
+		ws.subs.remove(sid)
+		data, _ := json.Marshal(types.ErrorMsg{Code: wsErr.Code, Msg: wsErr.Message})
+		msg := &types.WSMessage{Type: types.WSTypeError, SID: sid, Msg: data}
+		ws.recorder.recordClient(msg)
+		ws.deliver(msg)
+		ws.unroute(sid)
+	}
+}
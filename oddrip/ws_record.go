@@ -0,0 +1,326 @@
+package oddrip
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+// Directions of a recorded frame. WSRecordClient marks a message the
+// connection produced itself, such as a disconnected or reconnected event.
+const (
+	WSRecordRecv   = "recv"
+	WSRecordSent   = "sent"
+	WSRecordClient = "client"
+)
+
+// WSRecordEntry is one line of a session recording. A frame that is not valid
+// JSON is kept in Data instead of Frame.
+type WSRecordEntry struct {
+	Time  time.Time       `json:"time"`
+	Dir   string          `json:"dir"`
+	Frame json.RawMessage `json:"frame,omitempty"`
+	Data  []byte          `json:"data,omitempty"`
+}
+
+// WSRecorder writes a connection's session to w as JSON lines: every frame
+// read, every command sent and every client event, each stamped with the local
+// time. Frames carry the SIDs the consumer saw, so under WSReconnect they keep
+// their public SIDs across reconnects. A recorder can be shared by several
+// connections.
+type WSRecorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+func NewWSRecorder(w io.Writer) *WSRecorder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &WSRecorder{enc: enc}
+}
+
+// WSRecord records the connection's session to rec.
+func WSRecord(rec *WSRecorder) WSOption {
+	return func(o *wsOpts) {
+		o.recorder = rec
+	}
+}
+
+// Err returns the first write error. Recording stops after it.
+func (r *WSRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *WSRecorder) recordClient(msg *types.WSMessage) {
+	if r == nil {
+		return
+	}
+	frame, _ := json.Marshal(wsEnvelope{Type: msg.Type, SID: msg.SID, Seq: msg.Seq, Msg: msg.Msg})
+	r.record(WSRecordClient, frame)
+}
+
+func (r *WSRecorder) record(dir string, frame []byte) {
+	if r == nil {
+		return
+	}
+	e := WSRecordEntry{Time: time.Now(), Dir: dir}
+	if json.Valid(frame) {
+		e.Frame = frame
+	} else {
+		e.Data = frame
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.enc.Encode(e)
+	}
+}
+
+// WSReplay plays a recording back the way the connection delivered it. Frames
+// are routed by SID to a ReplaySubscription for each recorded subscription;
+// client events go to every subscription and to Messages, which also gets
+// frames without a SID. Sent commands and command responses are not
+// delivered. Replay a recording of a single connection; SIDs and command IDs
+// from several connections would collide.
+type WSReplay struct {
+	q      *wsQueue
+	subs   chan *ReplaySubscription
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	routes map[int]*ReplaySubscription
+	byID   map[int]*ReplaySubscription
+
+	mu  sync.Mutex
+	err error
+}
+
+// ReplaySubscription is a recorded subscription on a WSReplay.
+type ReplaySubscription struct {
+	q *wsQueue
+
+	mu       sync.Mutex
+	sids     []int
+	channels []string
+}
+
+// ReplayWS starts replaying the recording in r. With realtime the original
+// gaps between frames are kept; otherwise frames are delivered as fast as they
+// are read. Subscriptions block rather than drop, so nothing is lost and the
+// order is the recorded order; read every subscription or Close the replay.
+func ReplayWS(ctx context.Context, r io.Reader, realtime bool) *WSReplay {
+	ctx, cancel := context.WithCancel(ctx)
+	rp := &WSReplay{
+		q:      newWSQueue(wsOverflowEvict, new(atomic.Int64)),
+		subs:   make(chan *ReplaySubscription, wsMessageBuffer),
+		cancel: cancel,
+		done:   make(chan struct{}),
+		routes: make(map[int]*ReplaySubscription),
+		byID:   make(map[int]*ReplaySubscription),
+	}
+	go func() {
+		defer close(rp.done)
+		err := rp.play(ctx, r, realtime)
+		rp.mu.Lock()
+		rp.err = err
+		rp.mu.Unlock()
+		for _, sub := range rp.byID {
+			sub.q.close()
+		}
+		close(rp.subs)
+		rp.q.close()
+	}()
+	return rp
+}
+
+func (rp *WSReplay) play(ctx context.Context, r io.Reader, realtime bool) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 64<<20)
+	var first time.Time
+	start := time.Now()
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec WSRecordEntry
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return fmt.Errorf("replay line %d: %w", line, err)
+		}
+		if (rec.Dir != WSRecordRecv && rec.Dir != WSRecordClient) || rec.Frame == nil {
+			continue
+		}
+		var env wsEnvelope
+		if err := json.Unmarshal(rec.Frame, &env); err != nil {
+			// A live connection skips these too.
+			continue
+		}
+		if realtime {
+			if first.IsZero() {
+				first = rec.Time
+			}
+			if wait := time.Until(start.Add(rec.Time.Sub(first))); wait > 0 {
+				t := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return ctx.Err()
+				case <-t.C:
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if rec.Dir == WSRecordClient {
+			rp.deliverClient(ctx, &env)
+		} else if err := rp.deliver(ctx, &env); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// deliver routes a received frame as WSConn.readFrames and WSConn.deliver do.
+func (rp *WSReplay) deliver(ctx context.Context, env *wsEnvelope) error {
+	msg := &types.WSMessage{Type: env.Type, SID: env.SID, Seq: env.Seq, Msg: env.Msg}
+	switch {
+	case env.Type == types.WSTypeSubscribed:
+		var m types.SubscribedMsg
+		if json.Unmarshal(env.Msg, &m) != nil {
+			return nil
+		}
+		if _, ok := rp.routes[m.SID]; ok {
+			// Replayed after a reconnect under the same public SID.
+			return nil
+		}
+		sub := rp.byID[env.ID]
+		if sub == nil {
+			sub = &ReplaySubscription{q: newWSQueue(WSOverflowBlock, new(atomic.Int64))}
+			rp.byID[env.ID] = sub
+			select {
+			case rp.subs <- sub:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		sub.mu.Lock()
+		sub.sids = append(sub.sids, m.SID)
+		sub.channels = append(sub.channels, m.Channel)
+		sub.mu.Unlock()
+		rp.routes[m.SID] = sub
+	case env.Type == types.WSTypeUnsubscribed:
+		rp.unroute(env.SID)
+	case env.ID != 0 || env.Type == types.WSTypeOK:
+		// Answered to the command's caller.
+	case env.SID == 0:
+		rp.q.push(msg, ctx.Done())
+	default:
+		if sub, ok := rp.routes[env.SID]; ok {
+			sub.q.push(msg, ctx.Done())
+		}
+	}
+	return nil
+}
+
+// deliverClient replays a client event to every subscription, or a rejected
+// replay's error to its subscription, which then ends.
+func (rp *WSReplay) deliverClient(ctx context.Context, env *wsEnvelope) {
+	msg := &types.WSMessage{Type: env.Type, SID: env.SID, Seq: env.Seq, Msg: env.Msg}
+	if env.SID != 0 {
+		if sub, ok := rp.routes[env.SID]; ok {
+			sub.q.push(msg, ctx.Done())
+			rp.unroute(env.SID)
+		}
+		return
+	}
+	rp.q.push(msg, ctx.Done())
+	var seen []*ReplaySubscription
+	for _, sub := range rp.routes {
+		if !slices.Contains(seen, sub) {
+			seen = append(seen, sub)
+			sub.q.push(msg, ctx.Done())
+		}
+	}
+}
+
+func (rp *WSReplay) unroute(sid int) {
+	sub, ok := rp.routes[sid]
+	if !ok {
+		return
+	}
+	delete(rp.routes, sid)
+	sub.mu.Lock()
+	if i := slices.Index(sub.sids, sid); i >= 0 {
+		sub.sids = slices.Delete(sub.sids, i, i+1)
+		sub.channels = slices.Delete(sub.channels, i, i+1)
+	}
+	empty := len(sub.sids) == 0
+	sub.mu.Unlock()
+	if empty {
+		sub.q.close()
+	}
+}
+
+// Messages receives client events and frames without a SID. Like
+// WSConn.Messages it keeps only the newest frames when it is not read. It is
+// closed at the end of the recording, on Close or when the context ends.
+func (rp *WSReplay) Messages() <-chan *types.WSMessage {
+	return rp.q.ch
+}
+
+// Subscriptions yields each recorded subscription when its "subscribed"
+// response is replayed, and is closed when the replay ends.
+func (rp *WSReplay) Subscriptions() <-chan *ReplaySubscription {
+	return rp.subs
+}
+
+// Err returns the error that stopped the replay once Messages is closed, or
+// nil at the end of the recording.
+func (rp *WSReplay) Err() error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return rp.err
+}
+
+// Close stops the replay. It returns the error that stopped the replay
+// earlier, if any, such as a malformed line.
+func (rp *WSReplay) Close() error {
+	rp.cancel()
+	<-rp.done
+	if err := rp.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// Messages is closed when the recorded subscription is unsubscribed or
+// rejected, or when the replay ends.
+func (sub *ReplaySubscription) Messages() <-chan *types.WSMessage {
+	return sub.q.ch
+}
+
+// SIDs returns the subscription IDs as recorded, one per channel.
+func (sub *ReplaySubscription) SIDs() []int {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return slices.Clone(sub.sids)
+}
+
+// Channels returns the channel names, aligned with SIDs.
+func (sub *ReplaySubscription) Channels() []string {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return slices.Clone(sub.channels)
+}
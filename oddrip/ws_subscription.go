@@ -0,0 +1,139 @@
+package oddrip
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/UTXOnly/oddrip/oddrip/types"
+)
+
+const wsMessageBuffer = 256
+
+// Subscription is the handle returned by WSConn.Subscribe. Frames carrying
+// one of its SIDs are delivered on its Messages, as are client events
+// (disconnected, reconnected). Messages is closed after Unsubscribe or when
+// the connection ends.
+type Subscription struct {
+	ws       *WSConn
+	q        *wsQueue
+	sids     []int
+	channels []string
+}
+
+func (sub *Subscription) Messages() <-chan *types.WSMessage {
+	return sub.q.ch
+}
+
+// Dropped returns how many of this subscription's frames were discarded by
+// the overflow policy.
+func (sub *Subscription) Dropped() int64 {
+	return sub.q.dropped.Load()
+}
+
+// SIDs returns the subscription IDs, one per channel, in the order the server
+// confirmed them.
+func (sub *Subscription) SIDs() []int {
+	sub.ws.routeMu.Lock()
+	defer sub.ws.routeMu.Unlock()
+	return slices.Clone(sub.sids)
+}
+
+// Channels returns the channel names, aligned with SIDs.
+func (sub *Subscription) Channels() []string {
+	sub.ws.routeMu.Lock()
+	defer sub.ws.routeMu.Unlock()
+	return slices.Clone(sub.channels)
+}
+
+func (sub *Subscription) Unsubscribe(ctx context.Context) error {
+	sids := sub.SIDs()
+	if len(sids) == 0 {
+		return nil
+	}
+	return sub.ws.Unsubscribe(ctx, sids)
+}
+
+// register binds the SID in a "subscribed" frame to the subscription that
+// sent the command, before any data for that SID is read.
+func (ws *WSConn) register(env *wsEnvelope) {
+	ws.routeMu.Lock()
+	defer ws.routeMu.Unlock()
+	sub := ws.joining[env.ID]
+	if sub == nil {
+		return
+	}
+	var m types.SubscribedMsg
+	if json.Unmarshal(env.Msg, &m) != nil {
+		return
+	}
+	ws.routes[m.SID] = sub
+	sub.sids = append(sub.sids, m.SID)
+	sub.channels = append(sub.channels, m.Channel)
+}
+
+// deliver sends msg to the subscription owning its SID. Frames without a SID
+// and responses nobody waited for go to the shared channel; frames for a SID
+// no subscription owns, e.g. ones still in flight after Unsubscribe, are
+// dropped. It returns false when the overflow policy asks for a disconnect.
+func (ws *WSConn) deliver(msg *types.WSMessage) bool {
+	switch msg.Type {
+	case types.WSTypeSubscribed, types.WSTypeUnsubscribed, types.WSTypeOK:
+		return ws.emit(msg)
+	}
+	if msg.SID == 0 {
+		return ws.emit(msg)
+	}
+	ws.routeMu.Lock()
+	sub, ok := ws.routes[msg.SID]
+	ws.routeMu.Unlock()
+	if !ok {
+		return true
+	}
+	return sub.q.push(msg, ws.ctx.Done())
+}
+
+// broadcast sends msg to the shared channel and every subscription.
+func (ws *WSConn) broadcast(msg *types.WSMessage) {
+	ws.emit(msg)
+	ws.routeMu.Lock()
+	var subs []*Subscription
+	for _, sub := range ws.routes {
+		if !slices.Contains(subs, sub) {
+			subs = append(subs, sub)
+		}
+	}
+	ws.routeMu.Unlock()
+	for _, sub := range subs {
+		sub.q.push(msg, ws.ctx.Done())
+	}
+}
+
+// unroute drops sids and closes subscriptions left with none.
+func (ws *WSConn) unroute(sids ...int) {
+	ws.routeMu.Lock()
+	defer ws.routeMu.Unlock()
+	for _, sid := range sids {
+		sub, ok := ws.routes[sid]
+		if !ok {
+			continue
+		}
+		delete(ws.routes, sid)
+		if i := slices.Index(sub.sids, sid); i >= 0 {
+			sub.sids = slices.Delete(sub.sids, i, i+1)
+			sub.channels = slices.Delete(sub.channels, i, i+1)
+		}
+		if len(sub.sids) == 0 {
+			sub.q.close()
+		}
+	}
+}
+
+func (ws *WSConn) closeRoutes() {
+	ws.routeMu.Lock()
+	defer ws.routeMu.Unlock()
+	for sid, sub := range ws.routes {
+		delete(ws.routes, sid)
+		sub.q.close()
+	}
+}
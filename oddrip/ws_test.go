@@ -3,9 +3,15 @@ package oddrip
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -71,17 +77,18 @@ func TestConnectWS_Subscribe_Integration(t *testing.T) {
 	}
 	defer ws.Close()
 
-	subs, err := ws.Subscribe(ctx, types.SubscribeParams{
+	sub, err := ws.Subscribe(ctx, types.SubscribeParams{
 		Channels: []string{types.WSChannelTicker},
 	})
 	if err != nil {
 		t.Fatalf("Subscribe: %v", err)
 	}
-	if len(subs) != 1 {
-		t.Errorf("expected 1 subscribed, got %d", len(subs))
+	sids, channels := sub.SIDs(), sub.Channels()
+	if len(sids) != 1 {
+		t.Errorf("expected 1 subscribed, got %d", len(sids))
 	}
-	if len(subs) > 0 && (subs[0].Msg.Channel != types.WSChannelTicker || subs[0].Msg.SID != 1) {
-		t.Errorf("subscribed: channel=%s sid=%d", subs[0].Msg.Channel, subs[0].Msg.SID)
+	if len(sids) > 0 && (channels[0] != types.WSChannelTicker || sids[0] != 1) {
+		t.Errorf("subscribed: channel=%s sid=%d", channels[0], sids[0])
 	}
 }
 
@@ -117,3 +124,1199 @@ func (m *mockWSAuth) Apply(req *http.Request) error {
 	req.Header.Set("KALSHI-ACCESS-TIMESTAMP", "0")
 	return nil
 }
+
+func bookMsg(typ string, sid, seq int, msg string) *types.WSMessage {
+	return &types.WSMessage{Type: typ, SID: sid, Seq: seq, Msg: json.RawMessage(msg)}
+}
+
+func TestOrderBook_ApplySnapshotAndDeltas(t *testing.T) {
+	b := NewOrderBook(nil, "T")
+	steps := []*types.WSMessage{
+		bookMsg(types.WSTypeOrderbookSnapshot, 1, 1, `{"market_ticker":"T","yes_dollars_fp":[["0.4200","10.00"],["0.4000","5.00"]],"no_dollars_fp":[["0.5500","7.00"]]}`),
+		bookMsg(types.WSTypeOrderbookDelta, 1, 2, `{"market_ticker":"T","price_dollars":"0.4200","delta_fp":"-10.00","side":"yes"}`),
+		bookMsg(types.WSTypeOrderbookDelta, 1, 3, `{"market_ticker":"T","price_dollars":"0.4100","delta_fp":"3.50","side":"yes"}`),
+	}
+	for _, m := range steps {
+		if err := b.Apply(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bid, ask, ok := b.BestBidAsk("T")
+	if !ok || bid != (types.OrderbookLevel{0.41, 3.5}) || ask != (types.OrderbookLevel{0.45, 7}) {
+		t.Fatalf("bid %v ask %v ok %v", bid, ask, ok)
+	}
+	if l := b.Ladder("T", types.OrderSideYes); len(l) != 2 || l[1] != (types.OrderbookLevel{0.40, 5}) {
+		t.Fatalf("ladder %v", l)
+	}
+
+	err := b.Apply(bookMsg(types.WSTypeOrderbookDelta, 1, 5, `{"market_ticker":"T","price_dollars":"0.4100","delta_fp":"1.00","side":"yes"}`))
+	if !errors.Is(err, ErrOrderbookGap) || b.Ready("T") {
+		t.Fatalf("gap err %v ready %v", err, b.Ready("T"))
+	}
+	b = NewOrderBook(nil, "T")
+	b.Apply(steps[0])
+	err = b.Apply(bookMsg(types.WSTypeOrderbookDelta, 1, 2, `{"market_ticker":"T","price_dollars":"forty","delta_fp":"1.00","side":"yes"}`))
+	if err == nil || errors.Is(err, ErrOrderbookGap) || b.Ready("T") {
+		t.Fatalf("malformed err %v ready %v", err, b.Ready("T"))
+	}
+}
+
+func TestOrderBook_RunResyncs(t *testing.T) {
+	delta := func(seq int, side string) map[string]interface{} {
+		return map[string]interface{}{"type": "orderbook_delta", "sid": 1, "seq": seq, "msg": map[string]interface{}{"market_ticker": "T", "price_dollars": "0.3000", "delta_fp": "1.00", "side": side}}
+	}
+	for name, tc := range map[string]struct {
+		bad   map[string]interface{}
+		flood int
+	}{
+		"gap":       {bad: delta(3, "yes")},
+		"malformed": {bad: delta(2, "maybe")},
+		// Frames queued behind the gap fill the subscription under
+		// WSOverflowBlock while the unsubscribe is in flight.
+		"gap with full queue": {bad: delta(3, "yes"), flood: 2 * wsMessageBuffer},
+	} {
+		t.Run(name, func(t *testing.T) {
+			upgrader := websocket.Upgrader{}
+			cmds := make(chan string, 8)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				sid := 0
+				for {
+					_, data, err := conn.ReadMessage()
+					if err != nil {
+						return
+					}
+					var cmd struct {
+						ID  int    `json:"id"`
+						Cmd string `json:"cmd"`
+					}
+					json.Unmarshal(data, &cmd)
+					cmds <- cmd.Cmd
+					switch cmd.Cmd {
+					case "unsubscribe":
+						conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "unsubscribed", "sid": sid, "seq": 0})
+					case "subscribe":
+						sid++
+						conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": "orderbook_delta", "sid": sid}})
+						conn.WriteJSON(map[string]interface{}{"type": "orderbook_snapshot", "sid": sid, "seq": 1, "msg": map[string]interface{}{"market_ticker": "T", "yes_dollars_fp": [][]string{{"0.3000", "1.00"}}}})
+						if sid == 1 {
+							conn.WriteJSON(tc.bad)
+							for i := 0; i < tc.flood; i++ {
+								conn.WriteJSON(delta(4+i, "yes"))
+							}
+						}
+					}
+				}
+			}))
+			defer srv.Close()
+
+			u, _ := url.Parse(srv.URL)
+			client := New(Auth(&mockWSAuth{}))
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"), WSOverflow(WSOverflowBlock))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ws.Close()
+
+			b := NewOrderBook(ws, "T")
+			runCtx, stop := context.WithCancel(ctx)
+			done := make(chan error, 1)
+			go func() { done <- b.Run(runCtx) }()
+
+			for _, want := range []string{"subscribe", "unsubscribe", "subscribe"} {
+				select {
+				case got := <-cmds:
+					if got != want {
+						t.Fatalf("cmd %q, want %q", got, want)
+					}
+				case <-ctx.Done():
+					t.Fatalf("waiting for %s", want)
+				}
+			}
+			for !b.Ready("T") && ctx.Err() == nil {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if lvl, ok := b.BestBid("T", types.OrderSideYes); !ok || lvl != (types.OrderbookLevel{0.30, 1}) || b.Resyncs() != 1 {
+				t.Fatalf("best %v ok %v resyncs %d", lvl, ok, b.Resyncs())
+			}
+			stop()
+			if err := <-done; !errors.Is(err, context.Canceled) {
+				t.Fatalf("Run: %v", err)
+			}
+
+		})
+	}
+}
+
+type countingWSAuth struct{ n atomic.Int32 }
+
+func (a *countingWSAuth) Apply(req *http.Request) error {
+	req.Header.Set("KALSHI-ACCESS-TIMESTAMP", fmt.Sprint(a.n.Add(1)))
+	return nil
+}
+
+func TestWSReconnect_ReplaysAndKeepsSIDs(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var conns atomic.Int32
+	unsubSids := make(chan []int, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := conns.Add(1)
+		serverSID := 1
+		if n > 1 {
+			serverSID = 7
+		}
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID     int    `json:"id"`
+				Cmd    string `json:"cmd"`
+				Params struct {
+					Channels      []string `json:"channels"`
+					MarketTickers []string `json:"market_tickers"`
+					Sids          []int    `json:"sids"`
+				} `json:"params"`
+			}
+			json.Unmarshal(data, &cmd)
+			switch cmd.Cmd {
+			case "subscribe":
+				conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": cmd.Params.Channels[0], "sid": serverSID}})
+				conn.WriteJSON(map[string]interface{}{"type": "ticker", "sid": serverSID, "msg": map[string]interface{}{"market_ticker": cmd.Params.MarketTickers[0]}})
+				if n == 1 {
+					return // drop the first connection
+				}
+			case "unsubscribe":
+				unsubSids <- cmd.Params.Sids
+				conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "unsubscribed", "sid": serverSID, "seq": 1})
+			}
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	auth := &countingWSAuth{}
+	client := New(Auth(auth))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"),
+		WSReconnect(RetryConfig{InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	sub, err := ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelTicker}, MarketTickers: []string{"A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for len(got) < 4 {
+		select {
+		case msg := <-sub.Messages():
+			got = append(got, fmt.Sprintf("%s:%d", msg.Type, msg.SID))
+		case <-ctx.Done():
+			t.Fatalf("timed out after %v", got)
+		}
+	}
+	want := "ticker:1,disconnected:0,reconnected:0,ticker:1"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("got %v", got)
+	}
+	if auth.n.Load() != 2 {
+		t.Fatalf("handshake signed %d times", auth.n.Load())
+	}
+
+	if err := sub.Unsubscribe(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if sids := <-unsubSids; len(sids) != 1 || sids[0] != 7 {
+		t.Fatalf("unsubscribe sent %v", sids)
+	}
+	if _, ok := <-sub.Messages(); ok {
+		t.Fatal("subscription channel still open")
+	}
+}
+
+func TestWSReconnect_DropDuringReplay(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var conns atomic.Int32
+	var mu sync.Mutex
+	subscribes := map[int32][]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := conns.Add(1)
+		sid := 0
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID     int `json:"id"`
+				Params struct {
+					Channels []string `json:"channels"`
+				} `json:"params"`
+			}
+			json.Unmarshal(data, &cmd)
+			mu.Lock()
+			subscribes[n] = append(subscribes[n], cmd.Params.Channels[0])
+			mu.Unlock()
+			if n == 2 && sid == 1 {
+				return // drop the second connection in the middle of its replay
+			}
+			sid++
+			conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": cmd.Params.Channels[0], "sid": sid}})
+			if n == 1 && sid == 3 {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"),
+		WSReconnect(RetryConfig{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	for _, ch := range []string{types.WSChannelTicker, types.WSChannelTrade, types.WSChannelFill} {
+		if _, err := ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{ch}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for {
+		mu.Lock()
+		got := subscribes[3]
+		mu.Unlock()
+		if len(got) >= 3 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("third connection got %v", got)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(subscribes[3]) != "[ticker trade fill]" || conns.Load() != 3 {
+		t.Fatalf("conns %d, subscribes %v", conns.Load(), subscribes)
+	}
+}
+
+func TestWSKeepalive_StaleConnection(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Never read, so client pings go unanswered.
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"),
+		WSKeepalive(20*time.Millisecond, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	select {
+	case _, ok := <-ws.Messages():
+		if ok {
+			t.Fatal("unexpected message")
+		}
+	case <-ctx.Done():
+		t.Fatal("stale connection not detected")
+	}
+	if !errors.Is(ws.Err(), ErrWSStale) {
+		t.Fatalf("Err: %v", ws.Err())
+	}
+}
+
+func TestWSKeepalive_PongsKeepConnectionAlive(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			// Reading lets the default ping handler answer with pongs.
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ws, err := client.ConnectWS(context.Background(), WSScheme("ws"), WSHost(u.Host), WSPath("/"),
+		WSKeepalive(20*time.Millisecond, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	select {
+	case <-ws.Messages():
+		t.Fatalf("connection ended: %v", ws.Err())
+	case <-time.After(400 * time.Millisecond):
+	}
+}
+
+func TestWSSubscription_RoutesBySID(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sid := 0
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID     int    `json:"id"`
+				Cmd    string `json:"cmd"`
+				Params struct {
+					Channels []string `json:"channels"`
+				} `json:"params"`
+			}
+			json.Unmarshal(data, &cmd)
+			if cmd.Cmd != "subscribe" {
+				continue
+			}
+			for _, ch := range cmd.Params.Channels {
+				sid++
+				conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": ch, "sid": sid}})
+			}
+			if sid == 3 {
+				for _, s := range []int{1, 2, 3, 1} {
+					conn.WriteJSON(map[string]interface{}{"type": "ticker", "sid": s, "msg": map[string]interface{}{}})
+				}
+			}
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	a, err := ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelTicker}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelTrade, types.WSChannelFill}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(b.SIDs(), b.Channels()) != "[2 3] [trade fill]" {
+		t.Fatalf("b: %v %v", b.SIDs(), b.Channels())
+	}
+
+	read := func(sub *Subscription, n int) []int {
+		var sids []int
+		for len(sids) < n {
+			select {
+			case msg := <-sub.Messages():
+				sids = append(sids, msg.SID)
+			case <-ctx.Done():
+				t.Fatalf("timed out after %v", sids)
+			}
+		}
+		return sids
+	}
+	if got := read(a, 2); fmt.Sprint(got) != "[1 1]" {
+		t.Fatalf("a got %v", got)
+	}
+	if got := read(b, 2); fmt.Sprint(got) != "[2 3]" {
+		t.Fatalf("b got %v", got)
+	}
+}
+
+func TestWSQueue_OverflowPolicies(t *testing.T) {
+	fill := func(policy WSOverflowPolicy) (*wsQueue, *atomic.Int64) {
+		total := new(atomic.Int64)
+		q := newWSQueue(policy, total)
+		for i := 0; i < wsMessageBuffer; i++ {
+			if !q.push(&types.WSMessage{Type: "ticker", SID: 1, Seq: i + 1}, nil) {
+				t.Fatal("push on empty queue failed")
+			}
+		}
+		return q, total
+	}
+
+	q, total := fill(WSOverflowDropNewest)
+	q.push(&types.WSMessage{Type: "ticker", SID: 1, Seq: 1000}, nil)
+	if q.dropped.Load() != 1 || total.Load() != 1 {
+		t.Fatalf("dropped %d total %d", q.dropped.Load(), total.Load())
+	}
+	<-q.ch
+	<-q.ch
+	q.push(&types.WSMessage{Type: "ticker", SID: 1, Seq: 1001}, nil)
+	var last []*types.WSMessage
+	for len(q.ch) > 0 {
+		last = append(last, <-q.ch)
+	}
+	marker, next := last[len(last)-2], last[len(last)-1]
+	if marker.Type != types.WSTypeOverflow || marker.SID != 1 || string(marker.Msg) != `{"dropped":1}` || next.Seq != 1001 {
+		t.Fatalf("tail %+v %+v", marker, next)
+	}
+
+	q, _ = fill(WSOverflowDropOldest)
+	q.push(&types.WSMessage{Type: "ticker", SID: 1, Seq: 1000}, nil)
+	if first := <-q.ch; first.Seq != 2 || q.dropped.Load() != 1 {
+		t.Fatalf("first %+v dropped %d", first, q.dropped.Load())
+	}
+
+	q, _ = fill(WSOverflowDisconnect)
+	if q.push(&types.WSMessage{Type: "ticker", SID: 1}, nil) {
+		t.Fatal("disconnect policy accepted overflow")
+	}
+
+	q, _ = fill(WSOverflowBlock)
+	done := make(chan bool)
+	go func() { done <- q.push(&types.WSMessage{Type: "ticker", SID: 1, Seq: 1000}, nil) }()
+	select {
+	case <-done:
+		t.Fatal("block policy did not block")
+	case <-time.After(20 * time.Millisecond):
+	}
+	<-q.ch
+	if !<-done || q.dropped.Load() != 0 {
+		t.Fatal("blocked push not delivered")
+	}
+}
+
+func TestWSConn_ConcurrentCommandsAndClose(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sid := 0
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID     int    `json:"id"`
+				Cmd    string `json:"cmd"`
+				Params struct {
+					Sids []int `json:"sids"`
+				} `json:"params"`
+			}
+			json.Unmarshal(data, &cmd)
+			switch cmd.Cmd {
+			case "subscribe":
+				sid++
+				conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": "ticker", "sid": sid}})
+			case "unsubscribe":
+				for _, s := range cmd.Params.Sids {
+					conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "unsubscribed", "sid": s})
+				}
+			}
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sub, err := ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelTicker}})
+			if err == nil {
+				err = sub.Unsubscribe(ctx)
+			}
+			if err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if err := ws.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelTicker}}); !errors.Is(err, ErrWSClosed) {
+		t.Fatalf("Subscribe after Close: %v", err)
+	}
+}
+
+func TestSubscriptionManager_Reconcile(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var mu sync.Mutex
+	markets := map[int]map[string]bool{}
+	var cmds []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sid := 0
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID     int    `json:"id"`
+				Cmd    string `json:"cmd"`
+				Params struct {
+					Channels      []string `json:"channels"`
+					SID           int      `json:"sid"`
+					Sids          []int    `json:"sids"`
+					MarketTickers []string `json:"market_tickers"`
+					Action        string   `json:"action"`
+				} `json:"params"`
+			}
+			json.Unmarshal(data, &cmd)
+			mu.Lock()
+			cmds = append(cmds, fmt.Sprintf("%s:%s%d", cmd.Cmd, cmd.Params.Action, len(cmd.Params.MarketTickers)))
+			switch cmd.Cmd {
+			case "subscribe":
+				sid++
+				markets[sid] = map[string]bool{}
+				for _, m := range cmd.Params.MarketTickers {
+					markets[sid][m] = true
+				}
+				conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": cmd.Params.Channels[0], "sid": sid}})
+			case "update_subscription":
+				for _, m := range cmd.Params.MarketTickers {
+					if cmd.Params.Action == "add_markets" {
+						markets[cmd.Params.SID][m] = true
+					} else {
+						delete(markets[cmd.Params.SID], m)
+					}
+				}
+				conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "ok", "sid": cmd.Params.SID, "msg": map[string]interface{}{}})
+			case "unsubscribe":
+				for _, s := range cmd.Params.Sids {
+					delete(markets, s)
+					conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "unsubscribed", "sid": s})
+				}
+			case "list_subscriptions":
+				list := []map[string]interface{}{}
+				for s := range markets {
+					list = append(list, map[string]interface{}{"channel": "ticker", "sid": s})
+				}
+				conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "ok", "msg": list})
+			}
+			mu.Unlock()
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	state := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := fmt.Sprint(cmds)
+		cmds = nil
+		for s, ms := range markets {
+			var tickers []string
+			for m := range ms {
+				tickers = append(tickers, m)
+			}
+			sort.Strings(tickers)
+			out += fmt.Sprintf(" %d=%v", s, tickers)
+		}
+		return out
+	}
+
+	m := NewSubscriptionManager(ws, 2)
+	m.Set(types.WSChannelTicker, []string{"E", "D", "C", "B", "A"})
+	if err := m.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := state(); got != "[subscribe:2 update_subscription:add_markets2 update_subscription:add_markets1 list_subscriptions:0] 1=[A B C D E]" {
+		t.Fatalf("initial: %s", got)
+	}
+
+	m.Set(types.WSChannelTicker, []string{"A", "B", "F"})
+	if err := m.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := state(); got != "[update_subscription:add_markets1 update_subscription:delete_markets2 update_subscription:delete_markets1 list_subscriptions:0] 1=[A B F]" {
+		t.Fatalf("update: %s", got)
+	}
+
+	// The server loses the subscription; Reconcile subscribes again.
+	old := m.Subscription(types.WSChannelTicker)
+	mu.Lock()
+	delete(markets, 1)
+	mu.Unlock()
+	if err := m.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := state(); got != "[list_subscriptions:0 subscribe:2 update_subscription:add_markets1 list_subscriptions:0] 2=[A B F]" {
+		t.Fatalf("resubscribe: %s", got)
+	}
+	if _, ok := <-old.Messages(); ok {
+		t.Fatal("stale subscription still open")
+	}
+	if sids := m.Subscription(types.WSChannelTicker).SIDs(); fmt.Sprint(sids) != "[2]" {
+		t.Fatalf("new sids %v", sids)
+	}
+}
+
+func TestWSPool_ShardsAndMergesInOrder(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var keys atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID     int    `json:"id"`
+				Cmd    string `json:"cmd"`
+				Params struct {
+					ShardFactor int `json:"shard_factor"`
+					ShardKey    int `json:"shard_key"`
+				} `json:"params"`
+			}
+			json.Unmarshal(data, &cmd)
+			if cmd.Cmd != "subscribe" || cmd.Params.ShardFactor != 3 {
+				continue
+			}
+			keys.Add(1 << (cmd.Params.ShardKey * 4))
+			// Every connection assigns SID 1.
+			conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": "trade", "sid": 1}})
+			for seq := 1; seq <= 20; seq++ {
+				conn.WriteJSON(map[string]interface{}{"type": "trade", "sid": 1, "seq": seq, "msg": map[string]interface{}{"market_ticker": fmt.Sprint(cmd.Params.ShardKey)}})
+			}
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pool, err := client.ConnectWSPool(ctx, 3, WSScheme("ws"), WSHost(u.Host), WSPath("/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	sub, err := pool.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelTrade}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.Load() != 0x111 {
+		t.Fatalf("shard keys sent: %x", keys.Load())
+	}
+	if fmt.Sprint(sub.SIDs()) != "[1 2 3]" {
+		t.Fatalf("sids %v", sub.SIDs())
+	}
+
+	last := map[int]int{}
+	shardOf := map[int]string{}
+	for n := 0; n < 60; n++ {
+		select {
+		case msg := <-sub.Messages():
+			if msg.Seq != last[msg.SID]+1 {
+				t.Fatalf("sid %d: seq %d after %d", msg.SID, msg.Seq, last[msg.SID])
+			}
+			last[msg.SID] = msg.Seq
+			var tr types.TradeMsg
+			json.Unmarshal(msg.Msg, &tr)
+			if s, ok := shardOf[msg.SID]; ok && s != tr.MarketTicker {
+				t.Fatalf("sid %d mixes shards %s and %s", msg.SID, s, tr.MarketTicker)
+			}
+			shardOf[msg.SID] = tr.MarketTicker
+		case <-ctx.Done():
+			t.Fatalf("timed out after %v", last)
+		}
+	}
+	if len(shardOf) != 3 {
+		t.Fatalf("shards seen: %v", shardOf)
+	}
+
+	if _, err := pool.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelTrade}, ShardKey: new(int)}); err == nil {
+		t.Fatal("expected error for explicit shard key")
+	}
+}
+
+func TestWSPool_DedupesWhenShardingIgnored(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID  int    `json:"id"`
+				Cmd string `json:"cmd"`
+			}
+			json.Unmarshal(data, &cmd)
+			if cmd.Cmd != "subscribe" {
+				continue
+			}
+			// shard_factor and shard_key are ignored: every connection
+			// gets the same trades.
+			conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": "trade", "sid": 1}})
+			for seq := 1; seq <= 10; seq++ {
+				conn.WriteJSON(map[string]interface{}{"type": "trade", "sid": 1, "seq": seq, "msg": map[string]interface{}{"trade_id": fmt.Sprint("t", seq), "market_ticker": "T"}})
+			}
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pool, err := client.ConnectWSPool(ctx, 3, WSScheme("ws"), WSHost(u.Host), WSPath("/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	sub, err := pool.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelTrade}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trades := map[string]bool{}
+	for len(trades) < 10 {
+		select {
+		case msg := <-sub.Messages():
+			var tr types.TradeMsg
+			json.Unmarshal(msg.Msg, &tr)
+			if trades[tr.TradeID] {
+				t.Fatalf("trade %s delivered twice", tr.TradeID)
+			}
+			trades[tr.TradeID] = true
+		case <-ctx.Done():
+			t.Fatalf("timed out after %v", trades)
+		}
+	}
+	for sub.Duplicates() < 20 && ctx.Err() == nil {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := len(sub.Messages()); sub.Duplicates() != 20 || n != 0 {
+		t.Fatalf("duplicates %d, %d extra frames", sub.Duplicates(), n)
+	}
+}
+
+func TestWSRecord_ReplayRebuildsBook(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID int `json:"id"`
+			}
+			json.Unmarshal(data, &cmd)
+			conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": "orderbook_delta", "sid": 7}})
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"orderbook_snapshot","sid":7,"seq":1,"msg":{"market_ticker":"T","yes_dollars_fp":[["0.4200","10.00"]],"no_dollars_fp":[["0.5500","7.00"]]}}`))
+			conn.WriteMessage(websocket.TextMessage, []byte("<not json>"))
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"orderbook_delta","sid":7,"seq":2,"msg":{"market_ticker":"T","price_dollars":"0.4300","delta_fp":"2.00","side":"yes"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var buf strings.Builder
+	rec := NewWSRecorder(&buf)
+	ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"), WSRecord(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelOrderbookDelta}, MarketTickers: []string{"T"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		<-sub.Messages()
+	}
+	ws.Close()
+	if rec.Err() != nil {
+		t.Fatal(rec.Err())
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var first WSRecordEntry
+	json.Unmarshal([]byte(lines[0]), &first)
+	var raw WSRecordEntry
+	json.Unmarshal([]byte(lines[3]), &raw)
+	if len(lines) != 5 || first.Dir != WSRecordSent || !strings.Contains(string(first.Frame), `"cmd":"subscribe"`) || string(raw.Data) != "<not json>" {
+		t.Fatalf("recording:\n%s", buf.String())
+	}
+
+	rp := ReplayWS(ctx, strings.NewReader(buf.String()), false)
+	defer rp.Close()
+	rsub := <-rp.Subscriptions()
+	if rsub == nil || fmt.Sprint(rsub.SIDs(), rsub.Channels()) != "[7] [orderbook_delta]" {
+		t.Fatalf("replayed subscription %v", rsub)
+	}
+	book := NewOrderBook(nil, "T")
+	var got []string
+	for msg := range rsub.Messages() {
+		got = append(got, msg.Type)
+		if err := book.Apply(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rp.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != "[orderbook_snapshot orderbook_delta]" {
+		t.Fatalf("replayed %v", got)
+	}
+	if _, ok := <-rp.Subscriptions(); ok || len(rp.Messages()) != 0 {
+		t.Fatal("unexpected subscription or shared frames")
+	}
+	if bid, ok := book.BestBid("T", types.OrderSideYes); !ok || bid != (types.OrderbookLevel{0.43, 2}) {
+		t.Fatalf("best bid %v", bid)
+	}
+}
+
+func TestWSRecord_ReplayMatchesLiveAcrossReconnect(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var conns atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := conns.Add(1)
+		// The server assigns a new SID after the reconnect and restarts seq.
+		serverSID := 5
+		if n > 1 {
+			serverSID = 9
+		}
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID  int    `json:"id"`
+				Cmd string `json:"cmd"`
+			}
+			json.Unmarshal(data, &cmd)
+			if cmd.Cmd != "subscribe" {
+				continue
+			}
+			conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": "orderbook_delta", "sid": serverSID}})
+			conn.WriteJSON(map[string]interface{}{"type": "orderbook_snapshot", "sid": serverSID, "seq": 1, "msg": map[string]interface{}{"market_ticker": "T", "yes_dollars_fp": [][]string{{"0.4000", "1.00"}}}})
+			conn.WriteJSON(map[string]interface{}{"type": "orderbook_delta", "sid": serverSID, "seq": 2, "msg": map[string]interface{}{"market_ticker": "T", "price_dollars": fmt.Sprint("0.4", n, "00"), "delta_fp": "1.00", "side": "yes"}})
+			if n == 1 {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var buf strings.Builder
+	ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"), WSRecord(NewWSRecorder(&buf)),
+		WSReconnect(RetryConfig{InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelOrderbookDelta}, MarketTickers: []string{"T"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	describe := func(msg *types.WSMessage) string {
+		return fmt.Sprintf("%s:%d:%d:%s", msg.Type, msg.SID, msg.Seq, msg.Msg)
+	}
+	var live []string
+	for len(live) < 6 {
+		select {
+		case msg := <-sub.Messages():
+			live = append(live, describe(msg))
+		case <-ctx.Done():
+			t.Fatalf("timed out after %v", live)
+		}
+	}
+	ws.Close()
+
+	rp := ReplayWS(ctx, strings.NewReader(buf.String()), false)
+	defer rp.Close()
+	rsub, ok := <-rp.Subscriptions()
+	if !ok || fmt.Sprint(rsub.SIDs()) != fmt.Sprint(sub.SIDs()) {
+		t.Fatalf("replayed subscription %v", rsub)
+	}
+	book := NewOrderBook(nil, "T")
+	var replayed []string
+	for msg := range rsub.Messages() {
+		replayed = append(replayed, describe(msg))
+		if err := book.Apply(msg); err != nil {
+			t.Fatalf("apply %s: %v", describe(msg), err)
+		}
+	}
+	if strings.Join(replayed, "\n") != strings.Join(live, "\n") {
+		t.Fatalf("replayed:\n%s\nlive:\n%s", strings.Join(replayed, "\n"), strings.Join(live, "\n"))
+	}
+	if _, ok := <-rp.Subscriptions(); ok {
+		t.Fatal("replay subscribed twice")
+	}
+	var events []string
+	for msg := range rp.Messages() {
+		events = append(events, msg.Type)
+	}
+	if fmt.Sprint(events) != "[disconnected reconnected]" {
+		t.Fatalf("shared events %v", events)
+	}
+	if bid, ok := book.BestBid("T", types.OrderSideYes); !ok || bid != (types.OrderbookLevel{0.42, 1}) {
+		t.Fatalf("best bid %v", bid)
+	}
+}
+
+func TestWSReplay_Realtime(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.Encode(WSRecordEntry{Time: t0, Dir: WSRecordRecv, Frame: json.RawMessage(`{"id":2,"type":"subscribed","msg":{"channel":"ticker","sid":1}}`)})
+	for i, d := range []time.Duration{0, 60 * time.Millisecond, 120 * time.Millisecond} {
+		enc.Encode(WSRecordEntry{Time: t0.Add(d), Dir: WSRecordRecv, Frame: json.RawMessage(fmt.Sprintf(`{"type":"ticker","sid":1,"seq":%d,"msg":{}}`, i+1))})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	rp := ReplayWS(ctx, strings.NewReader(buf.String()), true)
+	n := 0
+	for range (<-rp.Subscriptions()).Messages() {
+		n++
+	}
+	if elapsed := time.Since(start); n != 3 || elapsed < 120*time.Millisecond {
+		t.Fatalf("got %d frames in %v", n, elapsed)
+	}
+
+	rp = ReplayWS(ctx, strings.NewReader("not json\n"), false)
+	for range rp.Messages() {
+	}
+	if rp.Err() == nil || rp.Close() == nil {
+		t.Fatal("expected error for corrupt recording")
+	}
+}
+
+func TestWSConn_MessagesUnreadUnderEachPolicy(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Frames without a SID or command ID go to Messages.
+		for seq := 1; seq <= wsMessageBuffer+40; seq++ {
+			conn.WriteJSON(map[string]interface{}{"type": "error", "seq": seq, "msg": map[string]interface{}{"code": 1}})
+		}
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID int `json:"id"`
+			}
+			json.Unmarshal(data, &cmd)
+			conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "ok", "msg": []interface{}{}})
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	policies := map[string]WSOverflowPolicy{
+		"drop newest": WSOverflowDropNewest,
+		"drop oldest": WSOverflowDropOldest,
+		"block":       WSOverflowBlock,
+		"disconnect":  WSOverflowDisconnect,
+	}
+	for name, policy := range policies {
+		t.Run(name, func(t *testing.T) {
+			client := New(Auth(&mockWSAuth{}))
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"), WSOverflow(policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ws.Close()
+
+			// The command is answered after every frame was read, so the
+			// read loop got past the full channel.
+			if _, err := ws.ListSubscriptions(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if ws.Dropped() != 0 || ws.Err() != nil {
+				t.Fatalf("dropped %d, err %v", ws.Dropped(), ws.Err())
+			}
+			if n := len(ws.Messages()); n != wsMessageBuffer {
+				t.Fatalf("buffered %d", n)
+			}
+			first := <-ws.Messages()
+			if first.Type != types.WSTypeError || first.Seq != 41 {
+				t.Fatalf("first %s seq %d", first.Type, first.Seq)
+			}
+		})
+	}
+}
+
+func TestWSSubscription_IndependentOfSharedMessages(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID  int    `json:"id"`
+				Cmd string `json:"cmd"`
+			}
+			json.Unmarshal(data, &cmd)
+			switch cmd.Cmd {
+			case "subscribe":
+				conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "subscribed", "msg": map[string]interface{}{"channel": "ticker", "sid": 1}})
+			case "list_subscriptions":
+				conn.WriteJSON(map[string]interface{}{"id": cmd.ID, "type": "ok", "msg": []interface{}{map[string]interface{}{"channel": "ticker", "sid": 1}}})
+				// A frame for a SID nobody owns, and one for the subscription.
+				conn.WriteJSON(map[string]interface{}{"type": "ticker", "sid": 7, "msg": map[string]interface{}{}})
+				conn.WriteJSON(map[string]interface{}{"type": "ticker", "sid": 1, "seq": cmd.ID, "msg": map[string]interface{}{}})
+			}
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := New(Auth(&mockWSAuth{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ws, err := client.ConnectWS(ctx, WSScheme("ws"), WSHost(u.Host), WSPath("/"), WSOverflow(WSOverflowBlock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	sub, err := ws.Subscribe(ctx, types.SubscribeParams{Channels: []string{types.WSChannelTicker}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the subscription is read; Messages is left alone.
+	for i := 0; i < 2*wsMessageBuffer; i++ {
+		if _, err := ws.ListSubscriptions(ctx); err != nil {
+			t.Fatalf("command %d: %v", i, err)
+		}
+		select {
+		case msg := <-sub.Messages():
+			if msg.SID != 1 {
+				t.Fatalf("sid %d", msg.SID)
+			}
+		case <-ctx.Done():
+			t.Fatalf("command %d: no frame", i)
+		}
+	}
+	if n := len(ws.Messages()); n != 0 || ws.Dropped() != 0 {
+		t.Fatalf("shared channel holds %d, dropped %d", n, ws.Dropped())
+	}
+}
@@ -0,0 +1,71 @@
+package oddrip
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const wsWriteWait = 10 * time.Second
+
+// wsWrite is one frame for the writer goroutine; the write error is sent on
+// done.
+type wsWrite struct {
+	msgType int
+	data    []byte
+	done    chan error
+}
+
+// writeLoop is the only goroutine that writes data frames to the socket, in
+// the order they were queued. It exits after writing a close frame or when
+// the connection's context ends. Control frames (ping/pong) go through
+// WriteControl, which gorilla allows concurrently.
+func (ws *WSConn) writeLoop() {
+	defer close(ws.writerDone)
+	for {
+		var req wsWrite
+		select {
+		case req = <-ws.writes:
+		case <-ws.ctx.Done():
+			return
+		}
+		ws.mu.Lock()
+		conn := ws.conn
+		ws.mu.Unlock()
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		err := conn.WriteMessage(req.msgType, req.data)
+		if err == nil && req.msgType == websocket.TextMessage {
+			ws.recorder.record(WSRecordSent, req.data)
+		}
+		req.done <- err
+		if req.msgType == websocket.CloseMessage {
+			return
+		}
+	}
+}
+
+// write queues a frame and waits until it is on the wire. It fails with
+// ErrWSClosed once the writer has shut down.
+func (ws *WSConn) write(ctx context.Context, msgType int, data []byte) error {
+	req := wsWrite{msgType: msgType, data: data, done: make(chan error, 1)}
+	select {
+	case ws.writes <- req:
+	case <-ws.writerDone:
+		return ErrWSClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-req.done:
+		return err
+	case <-ws.writerDone:
+		// The writer may have answered just before exiting.
+		select {
+		case err := <-req.done:
+			return err
+		default:
+			return ErrWSClosed
+		}
+	}
+}